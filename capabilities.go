@@ -0,0 +1,225 @@
+package tea
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// defaultCapabilityProbeTimeout is the default amount of time to wait for the
+// terminal to answer the capability probe.
+const defaultCapabilityProbeTimeout = 500 * time.Millisecond
+
+// PrimaryDeviceAttributesMsg is a message that represents the terminal
+// primary device attributes (DA1). It is sent by the terminal in response to
+// [ansi.RequestPrimaryDeviceAttributes].
+//
+// Common attributes include:
+//   - 4	Sixel
+//   - 18	Windowing capability
+//   - 22	ANSI color
+//
+// See [ansi.PrimaryDeviceAttributes] for more details.
+type PrimaryDeviceAttributesMsg []int
+
+// SecondaryDeviceAttributesMsg is a message that represents the terminal
+// secondary device attributes (DA2). It is sent by the terminal in response
+// to [ansi.RequestSecondaryDeviceAttributes].
+//
+// See [ansi.SecondaryDeviceAttributes] for more details.
+type SecondaryDeviceAttributesMsg []int
+
+// TerminalCapabilities describes what the terminal reported about itself.
+// Bubble Tea collects the terminal replies it sees while the program is
+// running. Use [WithCapabilityProbe] to actively query the terminal at
+// startup and receive the results as a [TerminalCapabilitiesMsg].
+//
+// Fields that the terminal didn't report are left at their zero value.
+type TerminalCapabilities struct {
+	// PrimaryDeviceAttributes holds the terminal primary device attributes
+	// (DA1).
+	PrimaryDeviceAttributes []int
+
+	// SecondaryDeviceAttributes holds the terminal secondary device
+	// attributes (DA2).
+	SecondaryDeviceAttributes []int
+
+	// TerminalVersion is the terminal name and version as reported by
+	// XTVERSION.
+	TerminalVersion string
+
+	// Termcap holds the Termcap/Terminfo capabilities reported by the
+	// terminal (XTGETTCAP). Boolean capabilities have an empty value.
+	Termcap map[string]string
+
+	// Modes holds the terminal mode reports (DECRPM) received so far.
+	Modes map[ansi.Mode]ansi.ModeSetting
+
+	// KeyboardEnhancements holds the keyboard enhancements reported by the
+	// terminal.
+	KeyboardEnhancements KeyboardEnhancementsMsg
+}
+
+// SupportsSixel returns whether the terminal advertises Sixel graphics in its
+// primary device attributes.
+func (c TerminalCapabilities) SupportsSixel() bool {
+	return slices.Contains(c.PrimaryDeviceAttributes, 4) //nolint:mnd
+}
+
+// SupportsTrueColor returns whether the terminal advertises the "RGB" or "Tc"
+// Termcap capabilities.
+func (c TerminalCapabilities) SupportsTrueColor() bool {
+	_, rgb := c.Termcap["RGB"]
+	_, tc := c.Termcap["Tc"]
+	return rgb || tc
+}
+
+// SupportsMode returns whether the terminal recognized the given mode when
+// it was queried with DECRQM.
+func (c TerminalCapabilities) SupportsMode(mode ansi.Mode) bool {
+	v, ok := c.Modes[mode]
+	return ok && !v.IsNotRecognized()
+}
+
+// SupportsSynchronizedOutput returns whether the terminal supports
+// synchronized output (mode 2026).
+func (c TerminalCapabilities) SupportsSynchronizedOutput() bool {
+	return c.SupportsMode(ansi.ModeSynchronizedOutput)
+}
+
+// SupportsUnicodeCore returns whether the terminal supports grapheme
+// clustering (mode 2027).
+func (c TerminalCapabilities) SupportsUnicodeCore() bool {
+	return c.SupportsMode(ansi.ModeUnicodeCore)
+}
+
+// SupportsColorSchemeReports returns whether the terminal supports reporting
+// light and dark color scheme changes (mode 2031).
+func (c TerminalCapabilities) SupportsColorSchemeReports() bool {
+	return c.SupportsMode(ansi.ModeLightDark)
+}
+
+// SupportsKeyboardEnhancements returns whether the terminal supports the
+// Kitty keyboard protocol.
+func (c TerminalCapabilities) SupportsKeyboardEnhancements() bool {
+	return c.KeyboardEnhancements.SupportsKeyDisambiguation()
+}
+
+// clone returns a deep copy of the capabilities so that it can be handed out
+// without sharing the underlying slices and maps.
+func (c TerminalCapabilities) clone() TerminalCapabilities {
+	c.PrimaryDeviceAttributes = slices.Clone(c.PrimaryDeviceAttributes)
+	c.SecondaryDeviceAttributes = slices.Clone(c.SecondaryDeviceAttributes)
+	c.Termcap = maps.Clone(c.Termcap)
+	c.Modes = maps.Clone(c.Modes)
+	return c
+}
+
+// update records the capability reported by the given message, if any.
+func (c *TerminalCapabilities) update(msg Msg) {
+	switch msg := msg.(type) {
+	case PrimaryDeviceAttributesMsg:
+		c.PrimaryDeviceAttributes = slices.Clone(msg)
+	case SecondaryDeviceAttributesMsg:
+		c.SecondaryDeviceAttributes = slices.Clone(msg)
+	case TerminalVersionMsg:
+		c.TerminalVersion = msg.Name
+	case CapabilityMsg:
+		if msg.Content == "" {
+			return
+		}
+		if c.Termcap == nil {
+			c.Termcap = make(map[string]string)
+		}
+		for _, tc := range strings.Split(msg.Content, ";") {
+			name, value, _ := strings.Cut(tc, "=")
+			c.Termcap[name] = value
+		}
+	case ModeReportMsg:
+		if c.Modes == nil {
+			c.Modes = make(map[ansi.Mode]ansi.ModeSetting)
+		}
+		c.Modes[msg.Mode] = msg.Value
+	case KeyboardEnhancementsMsg:
+		c.KeyboardEnhancements = msg
+	}
+}
+
+// TerminalCapabilitiesMsg is sent once the capability probe started by
+// [WithCapabilityProbe] has finished, either because the terminal answered
+// all queries or because the probe timed out. It holds everything the
+// terminal reported so far. The same information is available at any time
+// through [Program.Capabilities].
+//
+// Example:
+//
+//	func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+//	  switch msg := msg.(type) {
+//	  case tea.TerminalCapabilitiesMsg:
+//	    m.useSixel = msg.SupportsSixel()
+//	  }
+//	  return m, nil
+//	}
+type TerminalCapabilitiesMsg struct {
+	TerminalCapabilities
+}
+
+// capabilityProbeTimeoutMsg is an internal message that signals that the
+// capability probe ran out of time.
+type capabilityProbeTimeoutMsg struct{}
+
+// capabilityProbeQueries returns the queries sent by the capability probe.
+// The primary device attributes request goes last: every terminal answers it,
+// and since terminals answer in order, its reply marks the end of the probe.
+func capabilityProbeQueries() string {
+	return ansi.RequestNameVersion +
+		ansi.RequestSecondaryDeviceAttributes +
+		ansi.RequestTermcap("RGB") +
+		ansi.RequestTermcap("Tc") +
+		ansi.RequestModeSynchronizedOutput +
+		ansi.RequestModeUnicodeCore +
+		ansi.RequestModeLightDark +
+		ansi.RequestKittyKeyboard +
+		ansi.RequestPrimaryDeviceAttributes
+}
+
+// startCapabilityProbe queries the terminal for its capabilities. The results
+// are delivered as a [TerminalCapabilitiesMsg] once the terminal answered the
+// primary device attributes request or the probe timed out.
+func (p *Program) startCapabilityProbe() {
+	p.probing = true
+	p.execute(capabilityProbeQueries())
+	p.probeTimer = p.clock.AfterFunc(p.probeTimeout, func() {
+		p.Send(capabilityProbeTimeoutMsg{})
+	})
+}
+
+// updateCapabilities records the capability reported by msg and finishes the
+// capability probe when its sentinel reply, or its timeout, arrives.
+func (p *Program) updateCapabilities(msg Msg) {
+	p.capsMu.Lock()
+	p.caps.update(msg)
+	p.capsMu.Unlock()
+
+	if !p.probing {
+		return
+	}
+	switch msg.(type) {
+	case PrimaryDeviceAttributesMsg, capabilityProbeTimeoutMsg:
+		p.probing = false
+		p.probeTimer.Stop()
+		go p.Send(TerminalCapabilitiesMsg{p.Capabilities()})
+	}
+}
+
+// Capabilities returns the terminal capabilities reported so far. When the
+// program was created with [WithCapabilityProbe], the capabilities are
+// complete once the program has received a [TerminalCapabilitiesMsg].
+func (p *Program) Capabilities() TerminalCapabilities {
+	p.capsMu.RLock()
+	defer p.capsMu.RUnlock()
+	return p.caps.clone()
+}
@@ -0,0 +1,139 @@
+package tea
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestTerminalCapabilitiesUpdate(t *testing.T) {
+	t.Parallel()
+
+	var caps TerminalCapabilities
+	for _, msg := range []Msg{
+		PrimaryDeviceAttributesMsg{62, 4, 22},
+		SecondaryDeviceAttributesMsg{1, 95, 0},
+		TerminalVersionMsg{Name: "xterm(390)"},
+		CapabilityMsg{Content: "RGB=8/8/8"},
+		ModeReportMsg{Mode: ansi.ModeSynchronizedOutput, Value: ansi.ModeReset},
+		ModeReportMsg{Mode: ansi.ModeUnicodeCore, Value: ansi.ModeNotRecognized},
+		KeyboardEnhancementsMsg{Flags: 1},
+		KeyPressMsg{Code: 'a'}, // ignored
+	} {
+		caps.update(msg)
+	}
+
+	if !caps.SupportsSixel() {
+		t.Error("expected sixel support")
+	}
+	if !caps.SupportsTrueColor() {
+		t.Error("expected true color support")
+	}
+	if got := caps.Termcap["RGB"]; got != "8/8/8" {
+		t.Errorf("expected RGB capability to be %q, got %q", "8/8/8", got)
+	}
+	if !caps.SupportsSynchronizedOutput() {
+		t.Error("expected synchronized output support")
+	}
+	if caps.SupportsUnicodeCore() {
+		t.Error("expected no unicode core support")
+	}
+	if !caps.SupportsKeyboardEnhancements() {
+		t.Error("expected keyboard enhancements support")
+	}
+	if caps.TerminalVersion != "xterm(390)" {
+		t.Errorf("expected terminal version %q, got %q", "xterm(390)", caps.TerminalVersion)
+	}
+
+	// Clones must not share state with the original.
+	c := caps.clone()
+	c.Termcap["Tc"] = ""
+	c.PrimaryDeviceAttributes[1] = 0
+	if _, ok := caps.Termcap["Tc"]; ok || !caps.SupportsSixel() {
+		t.Error("expected clone to be independent of the original")
+	}
+}
+
+type capsModel struct {
+	caps chan TerminalCapabilities
+}
+
+func (m capsModel) Init() Cmd { return nil }
+
+func (m capsModel) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(TerminalCapabilitiesMsg); ok {
+		m.caps <- msg.TerminalCapabilities
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m capsModel) View() View { return NewView("probing") }
+
+func TestCapabilityProbe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reply", func(t *testing.T) {
+		t.Parallel()
+
+		pr, pw := io.Pipe()
+		defer pw.Close() //nolint:errcheck
+
+		var out bytes.Buffer
+		m := capsModel{caps: make(chan TerminalCapabilities, 1)}
+		p := NewProgram(m,
+			WithContext(t.Context()),
+			WithInput(pr),
+			WithOutput(&out),
+			WithoutSignals(),
+			WithCapabilityProbe(5*time.Second),
+		)
+
+		go func() {
+			// The terminal answers the queries in order, DA1 last.
+			_, _ = io.WriteString(pw, "\x1b[?2026;2$y\x1b[?62;4c")
+		}()
+
+		if _, err := p.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		caps := <-m.caps
+		if !caps.SupportsSixel() || !caps.SupportsSynchronizedOutput() {
+			t.Errorf("expected sixel and synchronized output support, got %+v", caps)
+		}
+		if !strings.Contains(out.String(), capabilityProbeQueries()) {
+			t.Errorf("expected probe queries in output, got %q", out.String())
+		}
+		if got := p.Capabilities(); !got.SupportsSixel() {
+			t.Errorf("expected program capabilities to be recorded, got %+v", got)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		pr, pw := io.Pipe()
+		defer pw.Close() //nolint:errcheck
+
+		m := capsModel{caps: make(chan TerminalCapabilities, 1)}
+		p := NewProgram(m,
+			WithContext(t.Context()),
+			WithInput(pr),
+			WithOutput(io.Discard),
+			WithoutSignals(),
+			WithCapabilityProbe(10*time.Millisecond),
+		)
+
+		if _, err := p.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if caps := <-m.caps; caps.PrimaryDeviceAttributes != nil {
+			t.Errorf("expected no device attributes, got %v", caps.PrimaryDeviceAttributes)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package tea
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and schedules functions. Bubble Tea uses the clock of
+// the program for everything that depends on time, like [Tick], [Every],
+// [Timeout], and the keymap and mouse gesture timeouts. Use [WithClock] to
+// replace it, for example with a [FakeClock] in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// AfterFunc calls f in its own goroutine once d has elapsed. The returned
+	// timer cancels the call.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a [Clock].
+type Timer interface {
+	// Stop prevents the timer from firing. It reports whether it stopped the
+	// timer, or false if the timer already fired or was stopped.
+	Stop() bool
+}
+
+// systemClock is the [Clock] of the system.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// after returns a channel that receives the current time once d has
+// elapsed on the given clock, along with the timer that cancels it.
+func after(c Clock, d time.Duration) (<-chan time.Time, Timer) {
+	ch := make(chan time.Time, 1)
+	t := c.AfterFunc(d, func() {
+		ch <- c.Now()
+	})
+	return ch, t
+}
+
+// clockKey is the context key of the program's clock.
+type clockKey struct{}
+
+// clockFromContext returns the clock stored in ctx, or the system clock.
+func clockFromContext(ctx context.Context) Clock {
+	if c, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return c
+	}
+	return systemClock{}
+}
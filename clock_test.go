@@ -0,0 +1,99 @@
+package tea
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	var fired []time.Time
+	clock.AfterFunc(2*time.Second, func() { fired = append(fired, clock.Now()) })
+	clock.AfterFunc(time.Second, func() {
+		fired = append(fired, clock.Now())
+		// Timers scheduled while advancing fire if they're due.
+		clock.AfterFunc(500*time.Millisecond, func() { fired = append(fired, clock.Now()) })
+	})
+	stopped := clock.AfterFunc(time.Second, func() { t.Error("expected the timer to be stopped") })
+	if !stopped.Stop() || stopped.Stop() {
+		t.Error("expected the timer to stop once")
+	}
+
+	clock.Advance(3 * time.Second)
+	want := []time.Time{
+		start.Add(time.Second),
+		start.Add(1500 * time.Millisecond),
+		start.Add(2 * time.Second),
+	}
+	if len(fired) != len(want) {
+		t.Fatalf("expected timers to fire at %v, got %v", want, fired)
+	}
+	for i := range want {
+		if !fired[i].Equal(want[i]) {
+			t.Errorf("expected timers to fire at %v, got %v", want, fired)
+		}
+	}
+	if now := clock.Now(); !now.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("expected the clock to be at %v, got %v", start.Add(3*time.Second), now)
+	}
+	if n := clock.Timers(); n != 0 {
+		t.Errorf("expected no pending timers, got %d", n)
+	}
+}
+
+type clockModel struct {
+	ticks int
+}
+
+type clockTickMsg struct{}
+
+func (m clockModel) Init() Cmd {
+	return Tick(time.Minute, func(time.Time) Msg { return clockTickMsg{} })
+}
+
+func (m clockModel) Update(msg Msg) (Model, Cmd) {
+	if _, ok := msg.(clockTickMsg); ok {
+		m.ticks++
+		if m.ticks == 3 {
+			return m, Quit
+		}
+		return m, m.Init()
+	}
+	return m, nil
+}
+
+func (m clockModel) View() View { return NewView("clock") }
+
+func TestWithClock(t *testing.T) {
+	t.Parallel()
+
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	p := NewProgram(clockModel{},
+		WithContext(t.Context()),
+		WithInput(nil),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+		WithClock(clock),
+	)
+
+	go func() {
+		// Minutes go by without waiting.
+		for range 3 {
+			clock.BlockUntilTimers(1)
+			clock.Advance(time.Minute)
+		}
+	}()
+
+	model, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticks := model.(clockModel).ticks; ticks != 3 {
+		t.Errorf("expected 3 ticks, got %d", ticks)
+	}
+}
@@ -0,0 +1,157 @@
+package tea
+
+import (
+	uv "github.com/charmbracelet/ultraviolet"
+)
+
+// maxPendingInput is the maximum number of input events held by the input
+// coalescing stage while the event loop is busy. Past that, we stop reading
+// input until the event loop catches up.
+const maxPendingInput = 1024
+
+// CoalescePolicy tells how consecutive input events of the same kind are
+// merged when the program can't keep up with the input.
+type CoalescePolicy int
+
+// Input coalescing policies.
+const (
+	// CoalesceNone delivers every event.
+	CoalesceNone CoalescePolicy = iota
+
+	// CoalesceLatest keeps only the latest of consecutive events.
+	CoalesceLatest
+
+	// CoalesceSum keeps the latest of consecutive events and adds up their
+	// steps. It only applies to mouse wheel events, where [Mouse.Delta] holds
+	// the number of steps. For other events, it's the same as
+	// [CoalesceLatest].
+	CoalesceSum
+)
+
+// InputCoalescing configures how input events are merged while the program
+// is busy. Events are only merged when they pile up because [Model.Update]
+// and [Model.View] take longer than the user takes to produce them, like when
+// moving the mouse with [MouseModeAllMotion], scrolling fast, or holding a
+// key down. Otherwise, every event is delivered as usual.
+//
+// Use [WithInputCoalescing] to enable input coalescing in a program.
+type InputCoalescing struct {
+	// MouseMotion is the policy for consecutive [MouseMotionMsg]s with the
+	// same buttons and modifiers.
+	MouseMotion CoalescePolicy
+
+	// MouseWheel is the policy for consecutive [MouseWheelMsg]s in the same
+	// direction and with the same modifiers.
+	MouseWheel CoalescePolicy
+
+	// KeyRepeat is the policy for consecutive repeats of the same key, when
+	// it's held down. Only key presses the terminal reports as repeats are
+	// merged, which requires [KeyboardEnhancements.ReportEventTypes]. The
+	// first press of a key is always delivered, and so are separate presses
+	// of the same key. Merging the repeats of a key that produces text drops
+	// the repeated characters.
+	KeyRepeat CoalescePolicy
+}
+
+// mouseWheelEvent is a mouse wheel event that stands for delta consecutive
+// wheel events.
+type mouseWheelEvent struct {
+	uv.MouseWheelEvent
+	delta int
+}
+
+// merge merges ev into the last pending event when the policies allow it, or
+// appends it to the pending events otherwise.
+func (c InputCoalescing) merge(pending []Msg, ev Msg) []Msg {
+	if len(pending) == 0 {
+		return append(pending, ev)
+	}
+
+	last := &pending[len(pending)-1]
+	switch ev := ev.(type) {
+	case uv.MouseMotionEvent:
+		if prev, ok := (*last).(uv.MouseMotionEvent); ok && c.MouseMotion != CoalesceNone &&
+			prev.Button == ev.Button && prev.Mod == ev.Mod {
+			*last = ev
+			return pending
+		}
+
+	case uv.MouseWheelEvent:
+		if c.MouseWheel == CoalesceNone {
+			break
+		}
+		var prev mouseWheelEvent
+		switch p := (*last).(type) {
+		case uv.MouseWheelEvent:
+			prev = mouseWheelEvent{MouseWheelEvent: p, delta: 1}
+		case mouseWheelEvent:
+			prev = p
+		default:
+			return append(pending, ev)
+		}
+		if prev.Button != ev.Button || prev.Mod != ev.Mod {
+			break
+		}
+		delta := 1
+		if c.MouseWheel == CoalesceSum {
+			delta += prev.delta
+		}
+		*last = mouseWheelEvent{MouseWheelEvent: ev, delta: delta}
+		return pending
+
+	case uv.KeyPressEvent:
+		if prev, ok := (*last).(uv.KeyPressEvent); ok && c.KeyRepeat != CoalesceNone &&
+			prev.IsRepeat && ev.IsRepeat && prev.Code == ev.Code && prev.Mod == ev.Mod && prev.Text == ev.Text {
+			*last = ev
+			return pending
+		}
+	}
+
+	return append(pending, ev)
+}
+
+// coalesceInput forwards the input events to the event loop, merging them
+// according to the program's coalescing policies while the event loop is
+// busy. It returns once events is closed.
+func (p *Program) coalesceInput(events <-chan Msg) {
+	var pending []Msg
+	for {
+		var (
+			in  = events
+			out chan Msg
+			msg Msg
+		)
+		if len(pending) > 0 {
+			out, msg = p.msgs, pending[0]
+		}
+		if len(pending) >= maxPendingInput {
+			in = nil
+		}
+
+		select {
+		case <-p.ctx.Done():
+			// Nobody is listening anymore, drain the input until the reader
+			// is done.
+			for range events { //nolint:revive
+			}
+			return
+
+		case ev, ok := <-in:
+			if !ok {
+				// The reader is done, deliver what's left.
+				for _, msg := range pending {
+					select {
+					case <-p.ctx.Done():
+						return
+					case p.msgs <- msg:
+					}
+				}
+				return
+			}
+			pending = p.coalescing.merge(pending, ev)
+
+		case out <- msg:
+			pending = pending[1:]
+		}
+	}
+}
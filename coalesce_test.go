@@ -0,0 +1,115 @@
+package tea
+
+import (
+	"reflect"
+	"testing"
+
+	uv "github.com/charmbracelet/ultraviolet"
+)
+
+func TestInputCoalescingMerge(t *testing.T) {
+	t.Parallel()
+
+	var (
+		motion = func(x int) uv.Event { return uv.MouseMotionEvent{X: x, Button: MouseLeft} }
+		wheel  = func(x int) uv.Event { return uv.MouseWheelEvent{X: x, Button: MouseWheelDown} }
+		up     = uv.KeyPressEvent{Code: KeyUp}
+		a      = uv.KeyPressEvent{Code: 'a', Text: "a"}
+	)
+
+	for _, tc := range []struct {
+		name   string
+		policy InputCoalescing
+		in     []Msg
+		want   []Msg
+	}{
+		{
+			name: "none",
+			in:   []Msg{motion(1), motion(2), wheel(1), wheel(2), up, up},
+			want: []Msg{motion(1), motion(2), wheel(1), wheel(2), up, up},
+		},
+		{
+			name:   "latest motion",
+			policy: InputCoalescing{MouseMotion: CoalesceLatest},
+			in:     []Msg{motion(1), motion(2), uv.MouseMotionEvent{X: 3}, motion(4), motion(5)},
+			want:   []Msg{motion(2), uv.MouseMotionEvent{X: 3}, motion(5)},
+		},
+		{
+			name:   "latest wheel",
+			policy: InputCoalescing{MouseWheel: CoalesceLatest},
+			in:     []Msg{wheel(1), wheel(2), wheel(3)},
+			want:   []Msg{mouseWheelEvent{MouseWheelEvent: wheel(3).(uv.MouseWheelEvent), delta: 1}},
+		},
+		{
+			name:   "sum wheel",
+			policy: InputCoalescing{MouseWheel: CoalesceSum},
+			in:     []Msg{wheel(1), wheel(2), wheel(3), uv.MouseWheelEvent{Button: MouseWheelUp}},
+			want: []Msg{
+				mouseWheelEvent{MouseWheelEvent: wheel(3).(uv.MouseWheelEvent), delta: 3},
+				uv.MouseWheelEvent{Button: MouseWheelUp},
+			},
+		},
+		{
+			name:   "key repeat",
+			policy: InputCoalescing{KeyRepeat: CoalesceLatest},
+			in: []Msg{
+				up, up, a, a,
+				uv.KeyPressEvent{Code: 'a', Text: "a", IsRepeat: true},
+				uv.KeyPressEvent{Code: 'a', Text: "a", IsRepeat: true},
+				uv.KeyPressEvent{Code: 'a', Text: "a", IsRepeat: true},
+			},
+			want: []Msg{up, up, a, a, uv.KeyPressEvent{Code: 'a', Text: "a", IsRepeat: true}},
+		},
+		{
+			name:   "interleaved",
+			policy: InputCoalescing{MouseMotion: CoalesceLatest, KeyRepeat: CoalesceLatest},
+			in:     []Msg{motion(1), up, motion(2), up},
+			want:   []Msg{motion(1), up, motion(2), up},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			var got []Msg
+			for _, ev := range tc.in {
+				got = tc.policy.merge(got, ev)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCoalesceInput(t *testing.T) {
+	t.Parallel()
+
+	p := NewProgram(nil, WithInputCoalescing(InputCoalescing{MouseWheel: CoalesceSum}))
+	p.ctx = t.Context()
+	p.msgs = make(chan Msg)
+
+	// The event loop is busy while the wheel events come in.
+	events := make(chan Msg)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.coalesceInput(events)
+	}()
+	for range 5 {
+		events <- uv.MouseWheelEvent{Button: MouseWheelDown}
+	}
+	events <- uv.KeyPressEvent{Code: 'q', Text: "q"}
+	close(events)
+
+	var got []Msg
+	for len(got) < 2 {
+		got = append(got, p.translateInputEvent(<-p.msgs))
+	}
+	<-done
+
+	if msg, ok := got[0].(MouseWheelMsg); !ok || msg.Delta != 5 {
+		t.Errorf("expected a wheel message with a delta of 5, got %#v", got[0])
+	}
+	if msg, ok := got[1].(KeyPressMsg); !ok || msg.String() != "q" {
+		t.Errorf("expected a q key press, got %#v", got[1])
+	}
+}
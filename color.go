@@ -89,3 +89,39 @@ func (e CursorColorMsg) String() string {
 func (e CursorColorMsg) IsDark() bool {
 	return uv.CursorColorEvent(e).IsDark()
 }
+
+// ColorSchemeMsg is sent when the terminal reports its color scheme. This
+// happens when [View.ReportColorScheme] is enabled, once with the current
+// scheme and then every time the user switches between light and dark
+// themes.
+//
+// Bubble Tea also requests the terminal background color when the scheme
+// changes, so a [BackgroundColorMsg] follows with the new background color.
+//
+// Example:
+//
+//	func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+//	  switch msg := msg.(type) {
+//	  case tea.ColorSchemeMsg:
+//	    m.styles = newStyles(msg.Dark)
+//	  }
+//	  return m, nil
+//	}
+//
+//	func (m model) View() tea.View {
+//	  v := tea.NewView(m.styles.Render("Hello!"))
+//	  v.ReportColorScheme = true
+//	  return v
+//	}
+type ColorSchemeMsg struct {
+	// Dark is true when the terminal uses a dark color scheme.
+	Dark bool
+}
+
+// String returns the name of the color scheme, "dark" or "light".
+func (e ColorSchemeMsg) String() string {
+	if e.Dark {
+		return "dark"
+	}
+	return "light"
+}
@@ -0,0 +1,299 @@
+package tea
+
+import (
+	"sync"
+	"time"
+)
+
+// Default retry policy.
+const (
+	defaultRetryAttempts   = 3
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultRetryMultiplier = 2
+)
+
+// timeoutMsg is used internally to run a command with a timeout.
+type timeoutMsg struct {
+	cmd       Cmd
+	d         time.Duration
+	onTimeout Msg
+}
+
+// Timeout returns a command that runs cmd and sends onTimeout if cmd doesn't
+// return within d. A command can't be interrupted, so the message cmd
+// eventually returns is dropped.
+//
+//	cmd := tea.Timeout(fetchUser(id), 5*time.Second, fetchTimeoutMsg{})
+func Timeout(cmd Cmd, d time.Duration, onTimeout Msg) Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() Msg {
+		return timeoutMsg{cmd: cmd, d: d, onTimeout: onTimeout}
+	}
+}
+
+// RetryPolicy configures how [Retry] retries a failing command. Zero values
+// use the defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times the command runs, including
+	// the first one. It defaults to 3.
+	MaxAttempts int
+
+	// Backoff is the amount of time to wait before the first retry. It
+	// defaults to 100ms.
+	Backoff time.Duration
+
+	// Multiplier multiplies the backoff after every retry. It defaults to 2.
+	Multiplier float64
+
+	// MaxBackoff caps the backoff. Zero means no cap.
+	MaxBackoff time.Duration
+
+	// ShouldRetry reports whether the given error is worth retrying. By
+	// default, every error is.
+	ShouldRetry func(error) bool
+}
+
+// retryMsg is used internally to retry a command.
+type retryMsg struct {
+	cmd    Cmd
+	policy RetryPolicy
+}
+
+// Retry returns a command that runs cmd again, with an exponential backoff,
+// while it returns a message that implements error. Once the command
+// succeeds, or after the last attempt, the message it returned is sent.
+//
+//	cmd := tea.Retry(fetchUser(id), tea.RetryPolicy{MaxAttempts: 5})
+func Retry(cmd Cmd, policy RetryPolicy) Cmd {
+	if cmd == nil {
+		return nil
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultRetryAttempts
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = defaultRetryBackoff
+	}
+	if policy.Multiplier <= 0 {
+		policy.Multiplier = defaultRetryMultiplier
+	}
+	return func() Msg {
+		return retryMsg{cmd: cmd, policy: policy}
+	}
+}
+
+// debounceMsg is used internally to debounce a command.
+type debounceMsg struct {
+	key string
+	d   time.Duration
+	cmd Cmd
+}
+
+// Debounce returns a command that runs cmd once no other command was
+// debounced with the same key for d. Every debounced command replaces the
+// pending one with the same key and restarts the delay. It's handy to react
+// to input only once the user stops typing:
+//
+//	func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+//	    switch msg.(type) {
+//	    case tea.KeyPressMsg:
+//	        m.query = ...
+//	        return m, tea.Debounce("search", 300*time.Millisecond, search(m.query))
+//	    }
+//	    return m, nil
+//	}
+//
+// In a [Sequence], Debounce only waits for the command to be scheduled.
+func Debounce(key string, d time.Duration, cmd Cmd) Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() Msg {
+		return debounceMsg{key: key, d: d, cmd: cmd}
+	}
+}
+
+// throttleMsg is used internally to throttle a command.
+type throttleMsg struct {
+	key string
+	d   time.Duration
+	cmd Cmd
+}
+
+// Throttle returns a command that runs cmd unless a command was already run
+// with the same key in the last d, in which case cmd is dropped.
+func Throttle(key string, d time.Duration, cmd Cmd) Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() Msg {
+		return throttleMsg{key: key, d: d, cmd: cmd}
+	}
+}
+
+// debouncer holds the state of the debounced and throttled commands of a
+// program.
+type debouncer struct {
+	mu        sync.Mutex
+	pending   map[string]*debounced
+	throttled map[string]time.Time
+}
+
+// debounced is a debounced command waiting to run.
+type debounced struct {
+	timer Timer
+}
+
+func (p *Program) execTimeoutMsg(msg timeoutMsg) {
+	if !p.disableCatchPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				p.recoverFromGoPanic(r)
+			}
+		}()
+	}
+
+	done := make(chan Msg, 1)
+	p.goCmd("", msg.cmd, func(msg Msg) { done <- msg }, p.recoverFromGoPanic)
+
+	expired, timer := after(p.clock, msg.d)
+	select {
+	case <-p.ctx.Done():
+		timer.Stop()
+	case result := <-done:
+		timer.Stop()
+		p.execMsg(result)
+	case <-expired:
+		p.execMsg(msg.onTimeout)
+	}
+}
+
+func (p *Program) execRetryMsg(msg retryMsg) {
+	if !p.disableCatchPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				p.recoverFromGoPanic(r)
+			}
+		}()
+	}
+
+	backoff := msg.policy.Backoff
+	for attempt := 1; ; attempt++ {
+		result := p.callCmd(msg.cmd)
+		if p.ctx.Err() != nil {
+			return
+		}
+		err, failed := result.(error)
+		if !failed || attempt >= msg.policy.MaxAttempts ||
+			(msg.policy.ShouldRetry != nil && !msg.policy.ShouldRetry(err)) {
+			p.execMsg(result)
+			return
+		}
+
+		wait, timer := after(p.clock, backoff)
+		select {
+		case <-p.ctx.Done():
+			timer.Stop()
+			return
+		case <-wait:
+		}
+
+		backoff = time.Duration(float64(backoff) * msg.policy.Multiplier)
+		if msg.policy.MaxBackoff > 0 {
+			backoff = min(backoff, msg.policy.MaxBackoff)
+		}
+	}
+}
+
+func (p *Program) execDebounceMsg(msg debounceMsg) {
+	d := &p.debouncer
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if prev, ok := d.pending[msg.key]; ok {
+		prev.timer.Stop()
+	}
+	if d.pending == nil {
+		d.pending = make(map[string]*debounced)
+	}
+
+	entry := &debounced{}
+	d.pending[msg.key] = entry
+	entry.timer = p.clock.AfterFunc(msg.d, func() {
+		d.mu.Lock()
+		current := d.pending[msg.key] == entry
+		if current {
+			delete(d.pending, msg.key)
+		}
+		d.mu.Unlock()
+
+		if current && p.ctx.Err() == nil {
+			p.execCmd(msg.cmd)
+		}
+	})
+}
+
+func (p *Program) execThrottleMsg(msg throttleMsg) {
+	d := &p.debouncer
+	now := p.clock.Now()
+
+	d.mu.Lock()
+	last, ok := d.throttled[msg.key]
+	run := !ok || now.Sub(last) >= msg.d
+	if run {
+		if d.throttled == nil {
+			d.throttled = make(map[string]time.Time)
+		}
+		d.throttled[msg.key] = now
+	}
+	d.mu.Unlock()
+
+	if run {
+		p.execCmd(msg.cmd)
+	}
+}
+
+// execCmd runs a command outside of the event loop and handles the message
+// it returns.
+func (p *Program) execCmd(cmd Cmd) {
+	if !p.disableCatchPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				p.recoverFromGoPanic(r)
+			}
+		}()
+	}
+	p.execMsg(p.callCmd(cmd))
+}
+
+// execMsg handles a message returned by a command run outside of the event
+// loop. Internal messages that run commands are run in place, and the others
+// are sent to the program.
+func (p *Program) execMsg(msg Msg) {
+	switch msg := msg.(type) {
+	case BatchMsg:
+		p.execBatchMsg(msg)
+	case sequenceMsg:
+		p.execSequenceMsg(msg)
+	case sequenceUntilMsg:
+		p.execSequenceUntilMsg(msg)
+	case streamMsg:
+		p.execStreamMsg(msg)
+	case timerMsg:
+		p.execTimerMsg(msg)
+	case timeoutMsg:
+		p.execTimeoutMsg(msg)
+	case retryMsg:
+		p.execRetryMsg(msg)
+	case debounceMsg:
+		p.execDebounceMsg(msg)
+	case throttleMsg:
+		p.execThrottleMsg(msg)
+	case groupMsg:
+		p.execGroupMsg(msg)
+	default:
+		p.Send(msg)
+	}
+}
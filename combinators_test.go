@@ -0,0 +1,171 @@
+package tea
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newCombinatorProgram(t *testing.T) (*Program, *FakeClock) {
+	t.Helper()
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	p := NewProgram(nil, WithContext(t.Context()), WithClock(clock))
+	p.msgs = make(chan Msg, 10)
+	return p, clock
+}
+
+func TestTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("expired", func(t *testing.T) {
+		t.Parallel()
+		p, clock := newCombinatorProgram(t)
+		release := make(chan struct{})
+		defer close(release)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			p.execMsg(Timeout(func() Msg {
+				<-release
+				return "result"
+			}, time.Second, "timeout")())
+		}()
+
+		clock.BlockUntilTimers(1)
+		clock.Advance(time.Second)
+		<-done
+		if msg := <-p.msgs; msg != "timeout" {
+			t.Errorf("expected timeout, got %v", msg)
+		}
+	})
+
+	t.Run("in time", func(t *testing.T) {
+		t.Parallel()
+		p, clock := newCombinatorProgram(t)
+		p.execMsg(Timeout(func() Msg { return "result" }, time.Second, "timeout")())
+		if msg := <-p.msgs; msg != "result" {
+			t.Errorf("expected result, got %v", msg)
+		}
+		clock.BlockUntilTimers(0)
+	})
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	p, clock := newCombinatorProgram(t)
+	attempts := 0
+	cmd := Retry(func() Msg {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("attempt %d failed", attempts)
+		}
+		return "ok"
+	}, RetryPolicy{MaxAttempts: 5, Backoff: time.Second})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.execMsg(cmd())
+	}()
+
+	// The backoff doubles after every retry.
+	clock.BlockUntilTimers(1)
+	clock.Advance(time.Second)
+	clock.BlockUntilTimers(1)
+	clock.Advance(time.Second)
+	select {
+	case <-done:
+		t.Fatal("expected the second retry to wait for 2s")
+	default:
+	}
+	clock.Advance(time.Second)
+	<-done
+
+	if msg := <-p.msgs; msg != "ok" {
+		t.Errorf("expected ok, got %v", msg)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	t.Parallel()
+
+	p, _ := newCombinatorProgram(t)
+	errFatal := errors.New("fatal")
+	attempts := 0
+	p.execMsg(Retry(func() Msg {
+		attempts++
+		return errFatal
+	}, RetryPolicy{ShouldRetry: func(err error) bool { return err != errFatal }})())
+
+	if msg := <-p.msgs; msg != errFatal {
+		t.Errorf("expected %v, got %v", errFatal, msg)
+	}
+	if attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	t.Parallel()
+
+	p, clock := newCombinatorProgram(t)
+	search := func(q string) Cmd {
+		return func() Msg { return q }
+	}
+
+	for _, q := range []string{"b", "bu", "bub"} {
+		p.execMsg(Debounce("search", 300*time.Millisecond, search(q))())
+		clock.Advance(100 * time.Millisecond)
+	}
+	p.execMsg(Debounce("other", 300*time.Millisecond, search("other"))())
+	clock.Advance(300 * time.Millisecond)
+
+	var got []Msg
+	for len(p.msgs) > 0 {
+		got = append(got, <-p.msgs)
+	}
+	if want := []Msg{"bub", "other"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	t.Parallel()
+
+	p, clock := newCombinatorProgram(t)
+	for i := range 5 {
+		p.execMsg(Throttle("save", time.Second, func() Msg { return i })())
+		clock.Advance(400 * time.Millisecond)
+	}
+
+	var got []Msg
+	for len(p.msgs) > 0 {
+		got = append(got, <-p.msgs)
+	}
+	if want := []Msg{0, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCombinatorsInSequence(t *testing.T) {
+	t.Parallel()
+
+	m, err := runStream(t, Sequence(
+		Timeout(func() Msg { return "a" }, time.Second, "timeout"),
+		Retry(func() Msg { return "b" }, RetryPolicy{}),
+		Throttle("c", time.Second, func() Msg { return "c" }),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Msg{"a", "b", "c"}; !reflect.DeepEqual(m.msgs, want) {
+		t.Errorf("expected %v, got %v", want, m.msgs)
+	}
+}
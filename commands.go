@@ -1,6 +1,7 @@
 package tea
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -29,6 +30,68 @@ func Sequence(cmds ...Cmd) Cmd {
 // sequenceMsg is used internally to run the given commands in order.
 type sequenceMsg []Cmd
 
+// SequenceUntil runs the given commands one at a time, in order, like
+// [Sequence], but stops at the first command that returns a message matching
+// pred. That message isn't sent as is: a [SequenceStoppedMsg] that wraps it is
+// sent instead. Only the messages returned by the commands themselves are
+// matched, not the ones of nested batches or sequences, with the exception of
+// a nested SequenceUntil: when it stops, its SequenceStoppedMsg is matched
+// as the message of the step.
+//
+// Use it with [IsError] to stop at the first failure:
+//
+//	cmd := tea.SequenceUntil(tea.IsError, authenticate, fetch, migrate)
+func SequenceUntil(pred func(Msg) bool, cmds ...Cmd) Cmd {
+	if pred == nil {
+		return Sequence(cmds...)
+	}
+	for _, cmd := range cmds {
+		if cmd != nil {
+			return func() Msg {
+				return sequenceUntilMsg{pred: pred, cmds: cmds}
+			}
+		}
+	}
+	return nil
+}
+
+// sequenceUntilMsg is used internally to run the given commands in order
+// until one returns a message matching pred.
+type sequenceUntilMsg struct {
+	pred func(Msg) bool
+	cmds []Cmd
+}
+
+// SequenceStoppedMsg is sent when a sequence created with [SequenceUntil]
+// stops. It implements error, so that a nested sequence stopped by an error
+// also stops the enclosing one when it uses [IsError].
+type SequenceStoppedMsg struct {
+	// Step is the index of the command that stopped the sequence, among the
+	// commands given to [SequenceUntil].
+	Step int
+
+	// Msg is the message the command returned.
+	Msg Msg
+}
+
+// Error implements error.
+func (m SequenceStoppedMsg) Error() string {
+	return fmt.Sprintf("sequence stopped at step %d: %v", m.Step, m.Msg)
+}
+
+// Unwrap returns the message that stopped the sequence, if it's an error.
+func (m SequenceStoppedMsg) Unwrap() error {
+	err, _ := m.Msg.(error)
+	return err
+}
+
+// IsError reports whether msg is an error. It's meant to be used with
+// [SequenceUntil].
+func IsError(msg Msg) bool {
+	_, ok := msg.(error)
+	return ok
+}
+
 // compactCmds ignores any nil commands in cmds, and returns the most direct
 // command possible. That is, considering the non-nil commands, if there are
 // none it returns nil, if there is exactly one it returns that command
@@ -98,18 +161,11 @@ func compactCmds[T ~[]Cmd](cmds []Cmd) Cmd {
 //	    return m, nil
 //	}
 //
-// Every is analogous to Tick in the Elm Architecture.
+// Every is analogous to Tick in the Elm Architecture. It uses the program's
+// clock, which can be replaced with [WithClock].
 func Every(duration time.Duration, fn func(time.Time) Msg) Cmd {
-	n := time.Now()
-	d := n.Truncate(duration).Add(duration).Sub(n)
-	t := time.NewTimer(d)
 	return func() Msg {
-		ts := <-t.C
-		t.Stop()
-		for len(t.C) > 0 {
-			<-t.C
-		}
-		return fn(ts)
+		return timerMsg{d: duration, every: true, fn: fn}
 	}
 }
 
@@ -151,15 +207,45 @@ func Every(duration time.Duration, fn func(time.Time) Msg) Cmd {
 //	    }
 //	    return m, nil
 //	}
+//
+// Tick uses the program's clock, which can be replaced with [WithClock].
 func Tick(d time.Duration, fn func(time.Time) Msg) Cmd {
-	t := time.NewTimer(d)
 	return func() Msg {
-		ts := <-t.C
-		t.Stop()
-		for len(t.C) > 0 {
-			<-t.C
-		}
-		return fn(ts)
+		return timerMsg{d: d, fn: fn}
+	}
+}
+
+// timerMsg is used internally to wait for a tick on the program's clock.
+// Like other internal messages, it's handled in the goroutine that ran the
+// command, so that a [Sequence] or [Batch] waits for the tick, but without
+// holding a command slot.
+type timerMsg struct {
+	d     time.Duration
+	every bool
+	fn    func(time.Time) Msg
+}
+
+func (p *Program) execTimerMsg(msg timerMsg) {
+	if !p.disableCatchPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				p.recoverFromGoPanic(r)
+			}
+		}()
+	}
+
+	d := msg.d
+	if msg.every {
+		// Tick in sync with the clock.
+		now := p.clock.Now()
+		d = now.Truncate(msg.d).Add(msg.d).Sub(now)
+	}
+	tick, timer := after(p.clock, d)
+	select {
+	case <-p.ctx.Done():
+		timer.Stop()
+	case t := <-tick:
+		p.execMsg(msg.fn(t))
 	}
 }
 
@@ -1,6 +1,8 @@
 package tea
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -8,25 +10,82 @@ import (
 func TestEvery(t *testing.T) {
 	t.Parallel()
 	expected := "every ms"
-	msg := Every(time.Millisecond, func(t time.Time) Msg {
+	msg := runTimerCmd(t, Every(time.Millisecond, func(t time.Time) Msg {
 		return expected
-	})()
+	}), time.Millisecond)
 	if expected != msg {
 		t.Fatalf("expected a msg %v but got %v", expected, msg)
 	}
 }
 
+func TestEveryInSync(t *testing.T) {
+	t.Parallel()
+
+	// The clock is 300ms past the second, so the tick happens 700ms later.
+	clock := NewFakeClock(time.Date(2025, 1, 1, 12, 34, 20, int(300*time.Millisecond), time.UTC))
+	p := NewProgram(nil, WithContext(t.Context()), WithClock(clock))
+	p.msgs = make(chan Msg, 1)
+	go p.execMsg(Every(time.Second, func(t time.Time) Msg { return t })())
+
+	clock.BlockUntilTimers(1)
+	clock.Advance(699 * time.Millisecond)
+	if n := len(p.msgs); n != 0 {
+		t.Fatalf("expected no tick yet, got %d", n)
+	}
+	clock.Advance(time.Millisecond)
+	if ts := (<-p.msgs).(time.Time); !ts.Equal(time.Date(2025, 1, 1, 12, 34, 21, 0, time.UTC)) {
+		t.Errorf("expected a tick on the second, got %v", ts)
+	}
+}
+
 func TestTick(t *testing.T) {
 	t.Parallel()
 	expected := "tick"
-	msg := Tick(time.Millisecond, func(t time.Time) Msg {
+	msg := runTimerCmd(t, Tick(time.Millisecond, func(t time.Time) Msg {
 		return expected
-	})()
+	}), time.Millisecond)
 	if expected != msg {
 		t.Fatalf("expected a msg %v but got %v", expected, msg)
 	}
 }
 
+func TestTickInSequence(t *testing.T) {
+	t.Parallel()
+
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	p := NewProgram(nil, WithContext(t.Context()), WithClock(clock))
+	p.msgs = make(chan Msg, 2)
+	go p.execMsg(Sequence(
+		Tick(time.Second, func(time.Time) Msg { return "tick" }),
+		func() Msg { return "after" },
+	)())
+
+	clock.BlockUntilTimers(1)
+	if n := len(p.msgs); n != 0 {
+		t.Fatalf("expected the sequence to wait for the tick, got %d messages", n)
+	}
+	clock.Advance(time.Second)
+	if msg := <-p.msgs; msg != "tick" {
+		t.Errorf("expected the tick first, got %v", msg)
+	}
+	if msg := <-p.msgs; msg != "after" {
+		t.Errorf("expected the next command after the tick, got %v", msg)
+	}
+}
+
+// runTimerCmd runs a time-based command on a fake clock advanced by d, and
+// returns its message.
+func runTimerCmd(t *testing.T, cmd Cmd, d time.Duration) Msg {
+	t.Helper()
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	p := NewProgram(nil, WithContext(t.Context()), WithClock(clock))
+	p.msgs = make(chan Msg, 1)
+	go p.execMsg(cmd())
+	clock.BlockUntilTimers(1)
+	clock.Advance(d)
+	return <-p.msgs
+}
+
 func TestBatch(t *testing.T) {
 	t.Parallel()
 	testMultipleCommands[BatchMsg](t, Batch)
@@ -37,6 +96,80 @@ func TestSequence(t *testing.T) {
 	testMultipleCommands[sequenceMsg](t, Sequence)
 }
 
+func TestSequenceUntil(t *testing.T) {
+	t.Parallel()
+
+	errFetch := errors.New("fetch failed")
+	step := func(msg Msg) Cmd {
+		return func() Msg { return msg }
+	}
+
+	t.Run("stopped", func(t *testing.T) {
+		t.Parallel()
+		m, err := runStream(t, SequenceUntil(IsError, step("auth"), nil, step(errFetch), step("migrate")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []Msg{"auth", SequenceStoppedMsg{Step: 2, Msg: errFetch}}
+		if !reflect.DeepEqual(m.msgs, want) {
+			t.Errorf("expected %v, got %v", want, m.msgs)
+		}
+		if stopped := m.msgs[1].(SequenceStoppedMsg); !errors.Is(stopped, errFetch) {
+			t.Errorf("expected %v to wrap %v", stopped, errFetch)
+		}
+	})
+
+	t.Run("completed", func(t *testing.T) {
+		t.Parallel()
+		m, err := runStream(t, SequenceUntil(IsError, step("auth"), step("migrate")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []Msg{"auth", "migrate"}; !reflect.DeepEqual(m.msgs, want) {
+			t.Errorf("expected %v, got %v", want, m.msgs)
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		t.Parallel()
+		m, err := runStream(t, SequenceUntil(IsError,
+			SequenceUntil(IsError, step("auth"), step(errFetch)),
+			step("after"),
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []Msg{"auth", SequenceStoppedMsg{Step: 0, Msg: SequenceStoppedMsg{Step: 1, Msg: errFetch}}}
+		if !reflect.DeepEqual(m.msgs, want) {
+			t.Errorf("expected %v, got %v", want, m.msgs)
+		}
+		if stopped := m.msgs[1].(SequenceStoppedMsg); !errors.Is(stopped, errFetch) {
+			t.Errorf("expected %v to wrap %v", stopped, errFetch)
+		}
+	})
+
+	t.Run("nested completed", func(t *testing.T) {
+		t.Parallel()
+		m, err := runStream(t, SequenceUntil(IsError,
+			SequenceUntil(IsError, step("auth"), step("fetch")),
+			step("after"),
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []Msg{"auth", "fetch", "after"}; !reflect.DeepEqual(m.msgs, want) {
+			t.Errorf("expected %v, got %v", want, m.msgs)
+		}
+	})
+
+	t.Run("nil cmds", func(t *testing.T) {
+		t.Parallel()
+		if cmd := SequenceUntil(IsError, nil, nil); cmd != nil {
+			t.Fatalf("expected nil, got %+v", cmd)
+		}
+	})
+}
+
 func testMultipleCommands[T ~[]Cmd](t *testing.T, createFn func(cmd ...Cmd) Cmd) {
 	t.Run("nil cmd", func(t *testing.T) {
 		t.Parallel()
@@ -0,0 +1,243 @@
+package tea
+
+import (
+	"sync"
+)
+
+// CommandStats reports the commands of a program that are running and the
+// ones waiting for the concurrency limits to allow them to run. See
+// [WithMaxConcurrentCommands] and [WithCommandGroupLimit].
+type CommandStats struct {
+	// InFlight is the number of commands running.
+	InFlight int
+
+	// Queued is the number of commands waiting to run.
+	Queued int
+
+	// Groups holds the stats of the commands of each group, by group name.
+	Groups map[string]CommandStats
+}
+
+// groupMsg is used internally to run a command in a group.
+type groupMsg struct {
+	group string
+	cmd   Cmd
+}
+
+// InGroup returns a command that runs cmd in the given group. The number of
+// commands of a group that run at once can be limited with
+// [WithCommandGroupLimit], in which case the commands in excess wait for the
+// running ones to return.
+//
+//	cmds := make([]tea.Cmd, 0, len(paths))
+//	for _, path := range paths {
+//	    cmds = append(cmds, tea.InGroup("io", stat(path)))
+//	}
+//	return m, tea.Batch(cmds...)
+func InGroup(group string, cmd Cmd) Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() Msg {
+		return groupMsg{group: group, cmd: cmd}
+	}
+}
+
+// cmdScheduler starts commands within the concurrency limits of a program.
+// Commands in excess are queued, in order, without a goroutine.
+type cmdScheduler struct {
+	mu      sync.Mutex
+	max     int
+	running int
+	queue   []queuedCmd
+	groups  map[string]*cmdGroup
+}
+
+// cmdGroup holds the limit and the counts of a command group.
+type cmdGroup struct {
+	max     int
+	running int
+	queued  int
+}
+
+// queuedCmd is a command waiting to start.
+type queuedCmd struct {
+	group string
+	start func()
+}
+
+// group returns the given group, creating it if needed. It must be called
+// with the lock held. The empty group is the program-wide one and has no
+// group.
+func (s *cmdScheduler) group(name string) *cmdGroup {
+	if name == "" {
+		return nil
+	}
+	if s.groups == nil {
+		s.groups = make(map[string]*cmdGroup)
+	}
+	g, ok := s.groups[name]
+	if !ok {
+		g = &cmdGroup{}
+		s.groups[name] = g
+	}
+	return g
+}
+
+// take takes a slot for a command of the given group, if the limits allow
+// it. It must be called with the lock held.
+func (s *cmdScheduler) take(g *cmdGroup) bool {
+	if s.max > 0 && s.running >= s.max {
+		return false
+	}
+	if g != nil {
+		if g.max > 0 && g.running >= g.max {
+			return false
+		}
+		g.running++
+	}
+	s.running++
+	return true
+}
+
+// schedule calls start once a command of the given group can run. start
+// must not block, and the command must call release once it returns.
+func (s *cmdScheduler) schedule(group string, start func()) {
+	s.mu.Lock()
+	g := s.group(group)
+	if !s.take(g) {
+		s.queue = append(s.queue, queuedCmd{group: group, start: start})
+		if g != nil {
+			g.queued++
+		}
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	start()
+}
+
+// release frees the slot of a command of the given group and starts the
+// queued commands that can run.
+func (s *cmdScheduler) release(group string) {
+	s.mu.Lock()
+	s.running--
+	if g := s.group(group); g != nil {
+		g.running--
+	}
+
+	var start []func()
+	queue := s.queue[:0]
+	for i, c := range s.queue {
+		if s.max > 0 && s.running >= s.max {
+			queue = append(queue, s.queue[i:]...)
+			break
+		}
+		g := s.group(c.group)
+		if !s.take(g) {
+			queue = append(queue, c)
+			continue
+		}
+		if g != nil {
+			g.queued--
+		}
+		start = append(start, c.start)
+	}
+	clear(s.queue[len(queue):])
+	s.queue = queue
+	s.mu.Unlock()
+
+	for _, fn := range start {
+		fn()
+	}
+}
+
+// stats returns the command stats.
+func (s *cmdScheduler) stats() CommandStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := CommandStats{InFlight: s.running, Queued: len(s.queue)}
+	if len(s.groups) > 0 {
+		stats.Groups = make(map[string]CommandStats, len(s.groups))
+		for name, g := range s.groups {
+			stats.Groups[name] = CommandStats{InFlight: g.running, Queued: g.queued}
+		}
+	}
+	return stats
+}
+
+// CommandStats returns the number of commands running and waiting to run.
+// It's safe to call from any goroutine.
+func (p *Program) CommandStats() CommandStats {
+	return p.commands.stats()
+}
+
+// goCmd runs cmd in its own goroutine once the concurrency limits allow it,
+// and then calls then with the message it returns. The command's slot is
+// freed before calling then. Panics are recovered with recoverFrom.
+func (p *Program) goCmd(group string, cmd Cmd, then func(Msg), recoverFrom func(any)) {
+	p.commands.schedule(group, func() {
+		go func() {
+			if !p.disableCatchPanics {
+				defer func() {
+					if r := recover(); r != nil {
+						recoverFrom(r)
+					}
+				}()
+			}
+
+			msg := func() Msg {
+				defer p.commands.release(group)
+				return cmd() // this can be long.
+			}()
+			then(msg)
+		}()
+	})
+}
+
+// callCmd runs cmd in the current goroutine once the concurrency limits
+// allow it, and returns the message it returns. It returns nil if the
+// program exits while waiting.
+func (p *Program) callCmd(cmd Cmd) Msg {
+	ready := make(chan struct{})
+	p.commands.schedule("", func() { close(ready) })
+	select {
+	case <-ready:
+	case <-p.ctx.Done():
+		// Don't leak the slot if we get it later.
+		go func() {
+			<-ready
+			p.commands.release("")
+		}()
+		return nil
+	}
+	defer p.commands.release("")
+	return cmd()
+}
+
+// handleCmdMsg handles the message returned by a command, and calls done, if
+// not nil, once it's handled. Grouped commands are queued without blocking.
+func (p *Program) handleCmdMsg(msg Msg, done func()) {
+	if g, ok := msg.(groupMsg); ok {
+		p.goCmd(g.group, g.cmd, func(msg Msg) {
+			p.handleCmdMsg(msg, done)
+		}, p.recoverFromGoPanic)
+		return
+	}
+	p.execMsg(msg)
+	if done != nil {
+		done()
+	}
+}
+
+// execGroupMsg runs a grouped command and waits for its message to be
+// handled.
+func (p *Program) execGroupMsg(msg groupMsg) {
+	done := make(chan struct{})
+	p.handleCmdMsg(msg, func() { close(done) })
+	select {
+	case <-done:
+	case <-p.ctx.Done():
+	}
+}
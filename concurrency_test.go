@@ -0,0 +1,143 @@
+package tea
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitCommandStats waits for the program's command stats to match want.
+func waitCommandStats(t *testing.T, p *Program, want CommandStats) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		got := p.CommandStats()
+		if reflect.DeepEqual(got, want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected command stats %+v, got %+v", want, got)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMaxConcurrentCommands(t *testing.T) {
+	t.Parallel()
+
+	p := NewProgram(nil, WithContext(t.Context()), WithMaxConcurrentCommands(2))
+	p.msgs = make(chan Msg, 10)
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	cmd := func() Msg {
+		n := running.Add(1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return "done"
+	}
+	batch := make(BatchMsg, 10)
+	for i := range batch {
+		batch[i] = cmd
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.execBatchMsg(batch)
+	}()
+
+	waitCommandStats(t, p, CommandStats{InFlight: 2, Queued: 8})
+	close(release)
+	<-done
+
+	if n := peak.Load(); n != 2 {
+		t.Errorf("expected at most 2 commands at once, got %d", n)
+	}
+	if n := len(p.msgs); n != 10 {
+		t.Errorf("expected 10 messages, got %d", n)
+	}
+	waitCommandStats(t, p, CommandStats{})
+}
+
+func TestMaxConcurrentCommandsTick(t *testing.T) {
+	t.Parallel()
+
+	clock := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	p := NewProgram(nil, WithContext(t.Context()), WithClock(clock), WithMaxConcurrentCommands(1))
+	p.msgs = make(chan Msg, 2)
+
+	// A pending tick doesn't keep the next command from running.
+	go p.execMsg(Batch(
+		Tick(time.Minute, func(time.Time) Msg { return "tick" }),
+		func() Msg { return "cmd" },
+	)())
+	if msg := <-p.msgs; msg != "cmd" {
+		t.Fatalf("expected the command to run before the tick, got %v", msg)
+	}
+
+	clock.BlockUntilTimers(1)
+	clock.Advance(time.Minute)
+	if msg := <-p.msgs; msg != "tick" {
+		t.Errorf("expected the tick, got %v", msg)
+	}
+}
+
+func TestCommandGroupLimit(t *testing.T) {
+	t.Parallel()
+
+	p := NewProgram(nil, WithContext(t.Context()), WithCommandGroupLimit("io", 1))
+	p.msgs = make(chan Msg, 10)
+
+	release := make(chan struct{})
+	io := InGroup("io", func() Msg {
+		<-release
+		return "io"
+	})
+	other := func() Msg {
+		<-release
+		return "other"
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.execBatchMsg(BatchMsg{io, io, io, other})
+	}()
+
+	// The grouped commands wait for each other, but not for the other
+	// command.
+	waitCommandStats(t, p, CommandStats{
+		InFlight: 2,
+		Queued:   2,
+		Groups:   map[string]CommandStats{"io": {InFlight: 1, Queued: 2}},
+	})
+	close(release)
+	<-done
+
+	if n := len(p.msgs); n != 4 {
+		t.Errorf("expected 4 messages, got %d", n)
+	}
+}
+
+func TestInGroupSequence(t *testing.T) {
+	t.Parallel()
+
+	m, err := runStream(t, Sequence(
+		InGroup("io", func() Msg { return "a" }),
+		func() Msg { return "b" },
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Msg{"a", "b"}; !reflect.DeepEqual(m.msgs, want) {
+		t.Errorf("expected %v, got %v", want, m.msgs)
+	}
+}
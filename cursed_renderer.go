@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"image/color"
 	"io"
+	"maps"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/charmbracelet/colorprofile"
 	uv "github.com/charmbracelet/ultraviolet"
@@ -35,6 +38,24 @@ type cursedRenderer struct {
 	starting      bool // indicates whether the renderer is starting after being stopped
 	pendingErase  bool // an scr.Erase() is pending and hasn't been drained by flush yet
 	noInput       bool // whether input is disabled, in which case keyboard enhancement queries are pointless
+
+	// The terminal row where the inline frame starts, as computed from
+	// cursor position reports. originQueries holds the cursor position
+	// reports we're waiting for, in order.
+	originY       int
+	originKnown   bool
+	originQueries []cursorQuery
+
+	// pixelsQueries is the number of pixel mouse mode reports we're waiting
+	// for.
+	pixelsQueries int
+
+	// The terminal colors we've set, as they were written. We compare new
+	// colors against them, rather than against the last view, so that we
+	// still reset them after the color profile changed.
+	termCursorColor color.Color
+	termFg, termBg  color.Color
+	termPalette     map[int]color.Color
 }
 
 var _ renderer = &cursedRenderer{}
@@ -114,35 +135,27 @@ func (s *cursedRenderer) start() {
 	}
 	enableTextCursor(s, s.lastView.Cursor != nil)
 	if s.lastView.Cursor != nil {
-		if s.lastView.Cursor.Color != nil {
-			col, ok := colorful.MakeColor(s.lastView.Cursor.Color)
-			if ok {
-				_, _ = s.scr.WriteString(ansi.SetCursorColor(col.Hex()))
-			}
-		}
+		setTermColor(s, &s.termCursorColor, s.convertColor(s.lastView.Cursor.Color),
+			ansi.SetCursorColor, ansi.ResetCursorColor)
 		curStyle := encodeCursorStyle(s.lastView.Cursor.Shape, s.lastView.Cursor.Blink)
 		if curStyle != 0 && curStyle != 1 {
 			_, _ = s.scr.WriteString(ansi.SetCursorStyle(curStyle))
 		}
 	}
-	if s.lastView.ForegroundColor != nil {
-		col, ok := colorful.MakeColor(s.lastView.ForegroundColor)
-		if ok {
-			_, _ = s.scr.WriteString(ansi.SetForegroundColor(col.Hex()))
-		}
-	}
-	if s.lastView.BackgroundColor != nil {
-		col, ok := colorful.MakeColor(s.lastView.BackgroundColor)
-		if ok {
-			_, _ = s.scr.WriteString(ansi.SetBackgroundColor(col.Hex()))
-		}
-	}
+	setTermColor(s, &s.termFg, s.convertColor(s.lastView.ForegroundColor),
+		ansi.SetForegroundColor, ansi.ResetForegroundColor)
+	setTermColor(s, &s.termBg, s.convertColor(s.lastView.BackgroundColor),
+		ansi.SetBackgroundColor, ansi.ResetBackgroundColor)
+	setPalette(s, s.lastView.Palette)
 	if !s.lastView.DisableBracketedPasteMode {
 		_, _ = s.scr.WriteString(ansi.SetModeBracketedPaste)
 	}
 	if s.lastView.ReportFocus {
 		_, _ = s.scr.WriteString(ansi.SetModeFocusEvent)
 	}
+	if s.lastView.ReportColorScheme {
+		_, _ = s.scr.WriteString(ansi.SetModeLightDark)
+	}
 	switch s.lastView.MouseMode {
 	case MouseModeNone:
 	case MouseModeCellMotion:
@@ -150,6 +163,12 @@ func (s *cursedRenderer) start() {
 	case MouseModeAllMotion:
 		_, _ = s.scr.WriteString(ansi.SetModeMouseAnyEvent + ansi.SetModeMouseExtSgr)
 	}
+	if mousePixels(s.lastView) {
+		_, _ = s.scr.WriteString(ansi.SetModeMouseExtSgrPixel)
+	}
+	if s.lastView.MousePointer != "" {
+		_, _ = s.scr.WriteString(ansi.SetPointerShape(string(s.lastView.MousePointer)))
+	}
 	if s.lastView.WindowTitle != "" {
 		_, _ = s.scr.WriteString(ansi.SetWindowTitle(s.lastView.WindowTitle))
 	}
@@ -211,6 +230,9 @@ func (s *cursedRenderer) close() (err error) {
 		if lv.ReportFocus {
 			_, _ = s.scr.WriteString(ansi.ResetModeFocusEvent)
 		}
+		if lv.ReportColorScheme {
+			_, _ = s.scr.WriteString(ansi.ResetModeLightDark)
+		}
 		switch lv.MouseMode {
 		case MouseModeNone:
 		case MouseModeCellMotion, MouseModeAllMotion:
@@ -218,6 +240,12 @@ func (s *cursedRenderer) close() (err error) {
 				ansi.ResetModeMouseAnyEvent +
 				ansi.ResetModeMouseExtSgr)
 		}
+		if mousePixels(lv) {
+			_, _ = s.scr.WriteString(ansi.ResetModeMouseExtSgrPixel)
+		}
+		if lv.MousePointer != "" {
+			_, _ = s.scr.WriteString(ansi.SetPointerShape(string(MousePointerDefault)))
+		}
 
 		if lv.WindowTitle != "" {
 			// Clear the window title if it was set.
@@ -230,18 +258,12 @@ func (s *cursedRenderer) close() (err error) {
 				// blinking block.
 				_, _ = s.scr.WriteString(ansi.SetCursorStyle(0))
 			}
-
-			if lc.Color != nil {
-				_, _ = s.scr.WriteString(ansi.ResetCursorColor)
-			}
 		}
 
-		if lv.BackgroundColor != nil {
-			_, _ = s.scr.WriteString(ansi.ResetBackgroundColor)
-		}
-		if lv.ForegroundColor != nil {
-			_, _ = s.scr.WriteString(ansi.ResetForegroundColor)
-		}
+		setTermColor(s, &s.termCursorColor, nil, ansi.SetCursorColor, ansi.ResetCursorColor)
+		setTermColor(s, &s.termBg, nil, ansi.SetBackgroundColor, ansi.ResetBackgroundColor)
+		setTermColor(s, &s.termFg, nil, ansi.SetForegroundColor, ansi.ResetForegroundColor)
+		setPalette(s, nil)
 		if lv.ProgressBar != nil && lv.ProgressBar.State != ProgressBarNone {
 			_, _ = s.scr.WriteString(ansi.ResetProgressBar)
 		}
@@ -322,6 +344,15 @@ func (s *cursedRenderer) flush(closing bool) error {
 		return nil
 	}
 
+	// Ask the terminal where the inline frame starts, so that mouse
+	// coordinates can be made relative to the frame. We only need it while
+	// the mouse is enabled, and ask again when the renderer starts, comes
+	// back from the alt screen, or enables the mouse, since the frame might
+	// have moved in the meantime.
+	if needsFrameOrigin(&view) && (s.starting || !needsFrameOrigin(s.lastView)) {
+		s.requestFrameOrigin(s.scr)
+	}
+
 	// We're no longer starting.
 	s.starting = false
 	s.pendingErase = false
@@ -343,6 +374,10 @@ func (s *cursedRenderer) flush(closing bool) error {
 	// we erase any old content.
 	s.cellbuf.Clear()
 	content.Draw(s.cellbuf, s.cellbuf.Bounds())
+	if s.profile == colorprofile.ASCII || s.profile == colorprofile.NoTTY {
+		// The terminal can't display any styles, drop them all.
+		stripStyles(s.cellbuf.Buffer)
+	}
 
 	// If the frame height is greater than the screen height, we drop the
 	// lines from the top of the buffer.
@@ -350,6 +385,12 @@ func (s *cursedRenderer) flush(closing bool) error {
 		s.cellbuf.Lines = s.cellbuf.Lines[frameHeight-s.height:]
 	}
 
+	// A frame that grows past the bottom of the screen scrolls the terminal
+	// and moves up.
+	if s.originKnown && !view.AltScreen {
+		s.originY = max(0, min(s.originY, s.height-frameArea.Dy()))
+	}
+
 	// Alt screen mode.
 	shouldUpdateAltScreen := (s.lastView == nil && view.AltScreen) || (s.lastView != nil && s.lastView.AltScreen != view.AltScreen)
 	if shouldUpdateAltScreen {
@@ -380,6 +421,20 @@ func (s *cursedRenderer) flush(closing bool) error {
 		}
 	}
 
+	// color scheme reports mode.
+	if s.lastView == nil || s.lastView.ReportColorScheme != view.ReportColorScheme {
+		if view.ReportColorScheme {
+			_, _ = s.scr.WriteString(ansi.SetModeLightDark)
+			if !s.noInput && !closing {
+				// Request the current color scheme, the terminal only
+				// reports changes.
+				_, _ = s.scr.WriteString(ansi.RequestLightDarkReport)
+			}
+		} else if s.lastView != nil {
+			_, _ = s.scr.WriteString(ansi.ResetModeLightDark)
+		}
+	}
+
 	// mouse events mode.
 	if s.lastView == nil || view.MouseMode != s.lastView.MouseMode {
 		switch view.MouseMode {
@@ -402,6 +457,38 @@ func (s *cursedRenderer) flush(closing bool) error {
 		}
 	}
 
+	// Mouse pointer shape.
+	if s.lastView == nil || view.MousePointer != s.lastView.MousePointer {
+		if view.MousePointer != "" {
+			_, _ = s.scr.WriteString(ansi.SetPointerShape(string(view.MousePointer)))
+		} else if s.lastView != nil {
+			_, _ = s.scr.WriteString(ansi.SetPointerShape(string(MousePointerDefault)))
+		}
+	}
+
+	// Pixel precision mouse coordinates. The mode is queried after every
+	// change so that the program knows how to read the mouse coordinates.
+	// The cell size, and the window size in pixels for terminals that don't
+	// report the cell size, are requested first so that they're known by
+	// then.
+	pixels := mousePixels(&view)
+	if pixels != mousePixels(s.lastView) {
+		if pixels {
+			_, _ = s.scr.WriteString(ansi.SetModeMouseExtSgrPixel)
+			if !s.noInput && !closing {
+				_, _ = s.scr.WriteString(ansi.WindowOp(ansi.RequestCellSizeWinOp) +
+					ansi.WindowOp(ansi.RequestWindowSizeWinOp) + ansi.RequestModeMouseExtSgrPixel)
+				s.pixelsQueries++
+			}
+		} else {
+			_, _ = s.scr.WriteString(ansi.ResetModeMouseExtSgrPixel)
+			if !s.noInput && !closing {
+				_, _ = s.scr.WriteString(ansi.RequestModeMouseExtSgrPixel)
+				s.pixelsQueries++
+			}
+		}
+	}
+
 	// Set window title.
 	if s.lastView == nil || view.WindowTitle != s.lastView.WindowTitle {
 		if s.lastView != nil || view.WindowTitle != "" {
@@ -442,44 +529,20 @@ func (s *cursedRenderer) flush(closing bool) error {
 		}
 	}
 
-	// Set terminal colors.
-	var (
-		cc, lcc  color.Color
-		lfg, lbg color.Color
-	)
+	// Set terminal colors. Colors are downsampled to the color profile
+	// first, so that we only emit colors the terminal can display.
+	var cc color.Color
 	if view.Cursor != nil {
-		cc = view.Cursor.Color
-	}
-	if s.lastView != nil {
-		if s.lastView.Cursor != nil {
-			lcc = s.lastView.Cursor.Color
-		}
-		lfg = s.lastView.ForegroundColor
-		lbg = s.lastView.BackgroundColor
-	}
-	for _, c := range []struct {
-		newColor color.Color
-		oldColor color.Color
-		reset    string
-		setter   func(string) string
-	}{
-		{newColor: cc, oldColor: lcc, reset: ansi.ResetCursorColor, setter: ansi.SetCursorColor},
-		{newColor: view.ForegroundColor, oldColor: lfg, reset: ansi.ResetForegroundColor, setter: ansi.SetForegroundColor},
-		{newColor: view.BackgroundColor, oldColor: lbg, reset: ansi.ResetBackgroundColor, setter: ansi.SetBackgroundColor},
-	} {
-		if c.newColor != c.oldColor {
-			if c.newColor == nil {
-				// Reset the color if it was set to nil.
-				_, _ = s.scr.WriteString(c.reset)
-			} else {
-				// Set the color.
-				col, ok := colorful.MakeColor(c.newColor)
-				if ok {
-					_, _ = s.scr.WriteString(c.setter(col.Hex()))
-				}
-			}
-		}
+		cc = s.convertColor(view.Cursor.Color)
 	}
+	setTermColor(s, &s.termCursorColor, cc, ansi.SetCursorColor, ansi.ResetCursorColor)
+	setTermColor(s, &s.termFg, s.convertColor(view.ForegroundColor),
+		ansi.SetForegroundColor, ansi.ResetForegroundColor)
+	setTermColor(s, &s.termBg, s.convertColor(view.BackgroundColor),
+		ansi.SetBackgroundColor, ansi.ResetBackgroundColor)
+
+	// Set or reset the palette colors that have changed.
+	setPalette(s, view.Palette)
 
 	// Set cursor shape and blink if set.
 	var ccStyle, lcStyle int
@@ -658,11 +721,46 @@ func reset(s *cursedRenderer) {
 // setColorProfile implements renderer.
 func (s *cursedRenderer) setColorProfile(p colorprofile.Profile) {
 	s.mu.Lock()
+	if s.lastView != nil && p != s.profile {
+		// What's on the screen was drawn with the previous profile, so we
+		// need a full redraw to display the new colors.
+		s.scr.Erase()
+		s.pendingErase = true
+	}
 	s.profile = p
 	s.scr.SetColorProfile(p)
 	s.mu.Unlock()
 }
 
+// convertColor downsamples the given color to the renderer's color profile.
+// It returns nil if the color is nil or the profile doesn't support colors.
+func (s *cursedRenderer) convertColor(c color.Color) color.Color {
+	if c == nil {
+		return nil
+	}
+	return s.profile.Convert(c)
+}
+
+// paletteColor returns the given palette color, or nil if the renderer's
+// color profile doesn't support colors. Unlike other colors, palette colors
+// aren't downsampled since they define what the ANSI colors look like.
+func (s *cursedRenderer) paletteColor(c color.Color) color.Color {
+	if s.profile == colorprofile.ASCII || s.profile == colorprofile.NoTTY {
+		return nil
+	}
+	return c
+}
+
+// stripStyles removes the styles, but not the hyperlinks, of all the cells in
+// the given buffer.
+func stripStyles(buf *uv.Buffer) {
+	for _, line := range buf.Lines {
+		for i := range line {
+			line[i].Style = uv.Style{}
+		}
+	}
+}
+
 // resize implements renderer.
 func (s *cursedRenderer) resize(w, h int) {
 	s.mu.Lock()
@@ -800,6 +898,11 @@ func (s *cursedRenderer) insertAbove(str string) error {
 
 	s.scr.SetPosition(0, 0)
 
+	// The frame moved down, find out where it starts now.
+	if needsFrameOrigin(s.lastView) {
+		s.requestFrameOrigin(&sb)
+	}
+
 	if s.logger != nil {
 		s.logger.Printf("insert above: %q", sb.String())
 	}
@@ -812,6 +915,125 @@ func (s *cursedRenderer) insertAbove(str string) error {
 	return nil
 }
 
+// cursorQueryTimeout is how long we wait for a cursor position report. Not
+// every terminal answers DECXCPR, and a request that's never answered must
+// not take the reports of the next ones.
+const cursorQueryTimeout = time.Second
+
+// cursorQuery is a cursor position request waiting for its report.
+type cursorQuery struct {
+	// row is the cursor row relative to the frame when we asked, or -1 for
+	// the program's requests.
+	row     int
+	expires time.Time
+}
+
+// queueCursorQuery records a cursor position request, and drops the ones
+// that have expired.
+func (s *cursedRenderer) queueCursorQuery(row int) {
+	now := time.Now()
+	s.expireCursorQueries(now)
+	s.originQueries = append(s.originQueries, cursorQuery{
+		row:     row,
+		expires: now.Add(cursorQueryTimeout),
+	})
+}
+
+// expireCursorQueries drops the cursor position requests that were never
+// answered.
+func (s *cursedRenderer) expireCursorQueries(now time.Time) {
+	i := 0
+	for i < len(s.originQueries) && now.After(s.originQueries[i].expires) {
+		i++
+	}
+	s.originQueries = s.originQueries[i:]
+}
+
+// requestFrameOrigin writes a cursor position request to w and records the
+// cursor row relative to the frame, so that the report tells where the frame
+// starts. We use DECXCPR since its report can't be mistaken for a key press.
+func (s *cursedRenderer) requestFrameOrigin(w io.StringWriter) {
+	if s.noInput {
+		return
+	}
+	// The position is negative until the renderer moved the cursor, which
+	// means the cursor is still on the first row of the frame.
+	_, y := s.scr.Position()
+	s.queueCursorQuery(max(y, 0))
+	_, _ = w.WriteString(ansi.RequestExtendedCursorPosition)
+}
+
+// needsFrameOrigin returns whether the renderer needs to know where the
+// frame of the given view starts, which is when the view enables the mouse on
+// the main screen.
+func needsFrameOrigin(v *View) bool {
+	return v != nil && !v.AltScreen && v.MouseMode != MouseModeNone
+}
+
+// requestCursorPosition implements renderer.
+func (s *cursedRenderer) requestCursorPosition() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.noInput {
+		return false
+	}
+	// The terminal reports both requests the same way, so we queue the
+	// program's request along with ours to tell the reports apart.
+	s.queueCursorQuery(-1)
+	_, _ = io.WriteString(s.w, ansi.RequestCursorPositionReport)
+	return true
+}
+
+// cursorPosition implements renderer.
+func (s *cursedRenderer) cursorPosition(_, y int, ambiguous bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expireCursorQueries(time.Now())
+	if len(s.originQueries) == 0 {
+		return false
+	}
+	row := s.originQueries[0].row
+	if ambiguous && row >= 0 {
+		// Our reports are never ambiguous, this one is a key press.
+		return false
+	}
+	s.originQueries = s.originQueries[1:]
+	if row < 0 {
+		// The report answers the program.
+		return false
+	}
+	s.originY = max(0, y-row)
+	s.originKnown = true
+	return true
+}
+
+// frameOrigin implements renderer.
+func (s *cursedRenderer) frameOrigin() (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastView != nil && s.lastView.AltScreen {
+		return 0, true
+	}
+	return s.originY, s.originKnown
+}
+
+// mousePixelsReported implements renderer.
+func (s *cursedRenderer) mousePixelsReported() {
+	s.mu.Lock()
+	s.pixelsQueries = max(0, s.pixelsQueries-1)
+	s.mu.Unlock()
+}
+
+// mousePixelsPending implements renderer.
+func (s *cursedRenderer) mousePixelsPending() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pixelsQueries > 0
+}
+
 // onMouse implements renderer.
 func (s *cursedRenderer) onMouse(m MouseMsg) Cmd {
 	var onMouse func(MouseMsg) Cmd
@@ -850,6 +1072,63 @@ func setProgressBar(s *cursedRenderer, pb *ProgressBar) {
 	}
 }
 
+// setTermColor sets the terminal color cur to c using the given set
+// sequence, or resets it when c is nil. Nothing is written if the terminal
+// already has that color.
+func setTermColor(s *cursedRenderer, cur *color.Color, c color.Color, set func(string) string, reset string) {
+	if c == *cur {
+		return
+	}
+	if c == nil {
+		_, _ = s.scr.WriteString(reset)
+		*cur = nil
+		return
+	}
+	if col, ok := colorful.MakeColor(c); ok {
+		_, _ = s.scr.WriteString(set(col.Hex()))
+		*cur = c
+	}
+}
+
+// setPalette writes the sequences that turn the palette colors we've set
+// into the ones in to. Colors missing from to are reset.
+func setPalette(s *cursedRenderer, to map[int]color.Color) {
+	indices := slices.Collect(maps.Keys(s.termPalette))
+	for i := range to {
+		if _, ok := s.termPalette[i]; !ok {
+			indices = append(indices, i)
+		}
+	}
+	slices.Sort(indices)
+	for _, i := range indices {
+		if !validPaletteIndex(i) {
+			continue
+		}
+		oc, nc := s.termPalette[i], s.paletteColor(to[i])
+		if oc == nc {
+			continue
+		}
+		if nc == nil {
+			_, _ = s.scr.WriteString(resetPaletteColor(i))
+			delete(s.termPalette, i)
+			continue
+		}
+		if col, ok := colorful.MakeColor(nc); ok {
+			_, _ = s.scr.WriteString(setPaletteColor(i, col.Hex()))
+			if s.termPalette == nil {
+				s.termPalette = make(map[int]color.Color)
+			}
+			s.termPalette[i] = nc
+		}
+	}
+}
+
+// mousePixels returns whether the view enables pixel precision mouse
+// coordinates.
+func mousePixels(v *View) bool {
+	return v != nil && v.MouseMode != MouseModeNone && v.MousePixels
+}
+
 func viewEquals(a, b *View) bool {
 	if a == nil || b == nil {
 		return false
@@ -859,11 +1138,15 @@ func viewEquals(a, b *View) bool {
 		a.AltScreen != b.AltScreen ||
 		a.DisableBracketedPasteMode != b.DisableBracketedPasteMode ||
 		a.ReportFocus != b.ReportFocus ||
+		a.ReportColorScheme != b.ReportColorScheme ||
 		a.MouseMode != b.MouseMode ||
+		a.MousePixels != b.MousePixels ||
+		a.MousePointer != b.MousePointer ||
 		a.WindowTitle != b.WindowTitle ||
 		a.ForegroundColor != b.ForegroundColor ||
 		a.BackgroundColor != b.BackgroundColor ||
-		a.KeyboardEnhancements != b.KeyboardEnhancements {
+		a.KeyboardEnhancements != b.KeyboardEnhancements ||
+		!maps.Equal(a.Palette, b.Palette) {
 		return false
 	}
 
@@ -3,12 +3,15 @@ package tea
 import (
 	"bytes"
 	"fmt"
+	"image/color"
 	"io"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/charmbracelet/colorprofile"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/lucasb-eyer/go-colorful"
 )
 
 type mouseRaceModel struct {
@@ -201,3 +204,387 @@ func TestCursedRenderer_updatesKittyKeyboardFlagsInPlace(t *testing.T) {
 		t.Fatalf("expected kitty keyboard protocol to be pushed once, got %d pushes in %q", n, got)
 	}
 }
+
+func TestCursedRenderer_reportColorScheme(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	r := newCursedRenderer(&out, []string{"TERM=xterm-256color"}, 80, 24)
+
+	render := func(v View) {
+		t.Helper()
+		r.render(v)
+		if err := r.flush(false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	view := NewView("hello")
+	view.ReportColorScheme = true
+	render(view)
+	view.ReportColorScheme = false
+	render(view)
+	view.ReportColorScheme = true
+	render(view)
+
+	if err := r.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Enabling the mode requests the current scheme, since terminals only
+	// report changes. Closing the renderer disables the mode again.
+	assertInOrder(t, out.String(),
+		ansi.SetModeLightDark, ansi.RequestLightDarkReport,
+		ansi.ResetModeLightDark,
+		ansi.SetModeLightDark, ansi.RequestLightDarkReport,
+		ansi.ResetModeLightDark,
+	)
+}
+
+func TestCursedRenderer_mousePixels(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	r := newCursedRenderer(&out, []string{"TERM=xterm-256color"}, 80, 24)
+
+	render := func(v View) {
+		t.Helper()
+		r.render(v)
+		if err := r.flush(false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	view := NewView("hello")
+	view.MouseMode = MouseModeCellMotion
+	view.MousePixels = true
+	render(view)
+	view.MouseMode = MouseModeNone
+	render(view)
+
+	if err := r.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cell size and the mode are queried after each change.
+	cellSize := ansi.WindowOp(ansi.RequestCellSizeWinOp)
+	windowSize := ansi.WindowOp(ansi.RequestWindowSizeWinOp)
+	assertInOrder(t, out.String(),
+		ansi.SetModeMouseExtSgr, ansi.SetModeMouseExtSgrPixel, cellSize, windowSize, ansi.RequestModeMouseExtSgrPixel,
+		ansi.ResetModeMouseExtSgrPixel, ansi.RequestModeMouseExtSgrPixel,
+	)
+	if n := strings.Count(out.String(), ansi.ResetModeMouseExtSgrPixel); n != 1 {
+		t.Errorf("expected pixel mode to be reset once, got %d times in %q", n, out.String())
+	}
+}
+
+func TestCursedRenderer_mousePointer(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	r := newCursedRenderer(&out, []string{"TERM=xterm-256color"}, 80, 24)
+
+	render := func(v View) {
+		t.Helper()
+		r.render(v)
+		if err := r.flush(false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	view := NewView("hello")
+	render(view)
+	view.MousePointer = MousePointerPointer
+	render(view)
+	view.MousePointer = MousePointerText
+	render(view)
+
+	if err := r.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := out.String()
+	assertInOrder(t, got,
+		ansi.SetPointerShape("pointer"),
+		ansi.SetPointerShape("text"),
+		ansi.SetPointerShape("default"),
+	)
+	if n := strings.Count(got, "\x1b]22;"); n != 3 {
+		t.Errorf("expected the pointer shape to be set 3 times, got %d in %q", n, got)
+	}
+}
+
+func TestCursedRenderer_frameOrigin(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	r := newCursedRenderer(&out, []string{"TERM=xterm-256color"}, 80, 24)
+	r.start()
+
+	render := func(v View) {
+		t.Helper()
+		r.render(v)
+		if err := r.flush(false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mouseView := func(content string) View {
+		v := NewView(content)
+		v.MouseMode = MouseModeCellMotion
+		return v
+	}
+
+	// Without the mouse, we don't need to know where the frame starts.
+	render(NewView("hello"))
+	if strings.Contains(out.String(), ansi.RequestExtendedCursorPosition) {
+		t.Fatalf("expected no cursor position request without the mouse, got %q", out.String())
+	}
+
+	render(mouseView("hello"))
+	if !strings.Contains(out.String(), ansi.RequestExtendedCursorPosition) {
+		t.Fatalf("expected enabling the mouse to request the cursor position, got %q", out.String())
+	}
+	if _, ok := r.frameOrigin(); ok {
+		t.Fatal("expected the frame origin to be unknown")
+	}
+
+	// The frame starts where the cursor was before the first frame.
+	if !r.cursorPosition(0, 20, false) {
+		t.Fatal("expected the report to answer the renderer")
+	}
+	if y, ok := r.frameOrigin(); !ok || y != 20 {
+		t.Errorf("expected the frame to start at row 20, got %d", y)
+	}
+	if r.cursorPosition(0, 20, false) {
+		t.Error("expected unrequested reports to be left alone")
+	}
+
+	// A frame that doesn't fit below the origin scrolls the terminal.
+	render(mouseView(strings.Repeat("line\n", 9) + "line"))
+	if y, _ := r.frameOrigin(); y != 14 {
+		t.Errorf("expected the frame to move up to row 14, got %d", y)
+	}
+
+	// Printing above the frame pushes it down.
+	out.Reset()
+	if err := r.insertAbove("above"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out.String(), ansi.RequestExtendedCursorPosition) {
+		t.Fatalf("expected insertAbove to request the cursor position, got %q", out.String())
+	}
+	// The program's own requests are answered in order with ours.
+	if !r.requestCursorPosition() {
+		t.Fatal("expected the renderer to send the program's request")
+	}
+	r.cursorPosition(0, 15, false)
+	if r.cursorPosition(0, 3, false) {
+		t.Error("expected the program's report to be left alone")
+	}
+	if y, _ := r.frameOrigin(); y != 15 {
+		t.Errorf("expected the frame to start at row 15, got %d", y)
+	}
+
+	render(View{Content: "alt", AltScreen: true})
+	if y, ok := r.frameOrigin(); !ok || y != 0 {
+		t.Errorf("expected the alt screen frame to start at row 0, got %d", y)
+	}
+}
+
+func TestCursedRenderer_frameOriginUnanswered(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	r := newCursedRenderer(&out, []string{"TERM=xterm-256color"}, 80, 24)
+	r.start()
+
+	v := NewView("hello")
+	v.MouseMode = MouseModeCellMotion
+	r.render(v)
+	if err := r.flush(false); err != nil {
+		t.Fatal(err)
+	}
+
+	// A modified F3 key looks like a report, but it doesn't answer us.
+	if r.cursorPosition(4, 0, true) {
+		t.Error("expected an ambiguous report to be left alone")
+	}
+
+	// The terminal never answers our request, so the program's request
+	// gets the next report.
+	r.originQueries[0].expires = time.Now().Add(-time.Millisecond)
+	if !r.requestCursorPosition() {
+		t.Fatal("expected the renderer to send the program's request")
+	}
+	if r.cursorPosition(0, 7, false) {
+		t.Error("expected the program's report to be left alone")
+	}
+	if _, ok := r.frameOrigin(); ok {
+		t.Error("expected the frame origin to be unknown")
+	}
+	if n := len(r.originQueries); n != 0 {
+		t.Errorf("expected no request to be left, got %d", n)
+	}
+}
+
+func TestCursedRenderer_palette(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	r := newCursedRenderer(&out, []string{"TERM=xterm-256color"}, 80, 24)
+	r.setColorProfile(colorprofile.TrueColor)
+
+	render := func(v View) {
+		t.Helper()
+		r.render(v)
+		if err := r.flush(false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	red := color.RGBA{R: 0xff, A: 0xff}
+	blue := color.RGBA{B: 0xff, A: 0xff}
+
+	view := NewView("hello")
+	view.Palette = map[int]color.Color{1: red, 4: blue}
+	render(view)
+	view.Palette = map[int]color.Color{1: blue}
+	render(view)
+
+	if err := r.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removed entries are reset, and closing the renderer resets the rest.
+	assertInOrder(t, out.String(),
+		"\x1b]4;1;#ff0000\x07", "\x1b]4;4;#0000ff\x07",
+		"\x1b]4;1;#0000ff\x07", "\x1b]104;4\x07",
+		"\x1b]104;1\x07",
+	)
+}
+
+func TestParsePaletteColor(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		seq  string
+		want string
+		ok   bool
+	}{
+		{seq: "\x1b]4;1;rgb:cdcd/0000/0000\x07", want: "#cd0000", ok: true},
+		{seq: "\x1b]4;255;rgb:eeee/eeee/eeee\x1b\\", want: "#eeeeee", ok: true},
+		{seq: "\x1b]4;256;rgb:eeee/eeee/eeee\x07"},
+		{seq: "\x1b]4;1;?\x07"},
+		{seq: "\x1b]11;rgb:0000/0000/0000\x07"},
+	} {
+		msg, ok := parsePaletteColor(tc.seq)
+		if ok != tc.ok {
+			t.Errorf("%q: expected ok to be %v, got %v", tc.seq, tc.ok, ok)
+			continue
+		}
+		if ok && msg.String() != tc.want {
+			t.Errorf("%q: expected %s, got %s", tc.seq, tc.want, msg)
+		}
+	}
+}
+
+func TestCursedRenderer_downsamplesColors(t *testing.T) {
+	t.Parallel()
+
+	const content = "\x1b[1;38;2;255;0;0mhello\x1b[m"
+	bg := color.RGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xff}
+
+	render := func(t *testing.T, profile colorprofile.Profile) string {
+		t.Helper()
+		var out bytes.Buffer
+		r := newCursedRenderer(&out, []string{"TERM=xterm-256color"}, 80, 24)
+		r.setColorProfile(profile)
+		view := NewView(content)
+		view.BackgroundColor = bg
+		view.Cursor = NewCursor(0, 0)
+		view.Cursor.Color = bg
+		r.render(view)
+		if err := r.flush(false); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.close(); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	t.Run("ansi256", func(t *testing.T) {
+		t.Parallel()
+		got := render(t, colorprofile.ANSI256)
+		if strings.Contains(got, "38;2;") {
+			t.Errorf("expected no truecolor sequences, got %q", got)
+		}
+		col, _ := colorful.MakeColor(ansi.Convert256(bg))
+		assertInOrder(t, got,
+			ansi.SetCursorColor(col.Hex()),
+			ansi.SetBackgroundColor(col.Hex()),
+			"\x1b[38;5;196;1mhello",
+			ansi.ResetCursorColor,
+			ansi.ResetBackgroundColor,
+		)
+	})
+
+	for _, profile := range []colorprofile.Profile{colorprofile.ASCII, colorprofile.NoTTY} {
+		t.Run(profile.String(), func(t *testing.T) {
+			t.Parallel()
+			got := render(t, profile)
+			if !strings.Contains(got, "hello") {
+				t.Fatalf("expected content to be rendered, got %q", got)
+			}
+			for _, seq := range []string{
+				"1mhello", "38;", "\x1b]11;", ansi.ResetBackgroundColor,
+				ansi.ResetCursorColor,
+			} {
+				if strings.Contains(got, seq) {
+					t.Errorf("expected no %q in output, got %q", seq, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCursedRenderer_resetsColorsAfterDowngrade(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	r := newCursedRenderer(&out, []string{"TERM=xterm-256color"}, 80, 24)
+	r.setColorProfile(colorprofile.TrueColor)
+
+	render := func(v View) {
+		t.Helper()
+		r.render(v)
+		if err := r.flush(false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bg := color.RGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xff}
+	view := NewView("hello")
+	view.BackgroundColor = bg
+	view.ForegroundColor = bg
+	view.Palette = map[int]color.Color{1: bg}
+	render(view)
+
+	// The colors we've set can't be displayed anymore, so they're reset.
+	r.setColorProfile(colorprofile.ASCII)
+	render(view)
+	mark := out.Len()
+
+	if err := r.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertInOrder(t, out.String(),
+		ansi.SetForegroundColor("#123456"), ansi.SetBackgroundColor("#123456"), "\x1b]4;1;#123456\x07",
+		ansi.ResetForegroundColor, ansi.ResetBackgroundColor, "\x1b]104;1\x07",
+	)
+	if tail := out.String()[mark:]; strings.Contains(tail, "\x1b]11") || strings.Contains(tail, "\x1b]104") {
+		t.Errorf("expected no color reset when closing, got %q", tail)
+	}
+}
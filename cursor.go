@@ -23,6 +23,31 @@ type requestCursorPosMsg struct{}
 
 // RequestCursorPosition is a command that requests the cursor position.
 // The cursor position will be sent as a [CursorPositionMsg] message.
+//
+// While the mouse is enabled in an inline program, Bubble Tea also requests
+// the cursor position to find where the frame starts. Requests made with this
+// command are told apart from those, but cursor position requests sent with
+// [Raw] are not, and their reports may be taken for Bubble Tea's own.
 func RequestCursorPosition() Msg {
 	return requestCursorPosMsg{}
 }
+
+// frameOriginReport reports whether msg is a cursor position report that
+// answers the renderer, in which case the program doesn't receive it.
+func (p *Program) frameOriginReport(msg Msg) bool {
+	f3 := p.modifiedF3
+	p.modifiedF3 = 0
+
+	switch msg := msg.(type) {
+	case KeyPressMsg:
+		if msg.Code == KeyF3 && msg.Mod != 0 {
+			p.modifiedF3 = msg.Mod
+		}
+	case CursorPositionMsg:
+		// A modified F3 key (CSI 1;<mod> R) is reported both as a key press
+		// and as a cursor position report, since it's the same sequence.
+		ambiguous := f3 != 0 && msg.Y == 0 && msg.X == int(f3)
+		return p.renderer.cursorPosition(msg.X, msg.Y, ambiguous)
+	}
+	return false
+}
@@ -21,25 +21,18 @@ type responseMsg struct{}
 // In this case, we'll send events on the channel at a random interval between
 // 100 to 1000 milliseconds. As a command, Bubble Tea will run this
 // asynchronously.
-func listenForActivity(sub chan struct{}) tea.Cmd {
+func listenForActivity(sub chan responseMsg) tea.Cmd {
 	return func() tea.Msg {
 		for {
 			time.Sleep(time.Millisecond * time.Duration(rand.Int63n(900)+100)) // nolint:gosec
-			sub <- struct{}{}
+			sub <- responseMsg{}
 		}
 	}
 }
 
-// A command that waits for the activity on a channel.
-func waitForActivity(sub chan struct{}) tea.Cmd {
-	return func() tea.Msg {
-		return responseMsg(<-sub)
-	}
-}
-
 type model struct {
-	sub       chan struct{} // where we'll receive activity notifications
-	responses int           // how many responses we've received
+	sub       chan responseMsg // where we'll receive activity notifications
+	responses int              // how many responses we've received
 	spinner   spinner.Model
 	quitting  bool
 }
@@ -47,8 +40,8 @@ type model struct {
 func (m model) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
-		listenForActivity(m.sub), // generate activity
-		waitForActivity(m.sub),   // wait for activity
+		listenForActivity(m.sub),           // generate activity
+		tea.FromChannel("activity", m.sub), // deliver every activity as a message
 	)
 }
 
@@ -58,8 +51,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.quitting = true
 		return m, tea.Quit
 	case responseMsg:
-		m.responses++                    // record external activity
-		return m, waitForActivity(m.sub) // wait for next event
+		m.responses++ // record external activity
+		return m, nil
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -79,7 +72,7 @@ func (m model) View() tea.View {
 
 func main() {
 	p := tea.NewProgram(model{
-		sub:     make(chan struct{}),
+		sub:     make(chan responseMsg),
 		spinner: spinner.New(),
 	})
 
@@ -70,17 +70,14 @@ func (m model) View() tea.View {
 }
 
 func main() {
-	p := tea.NewProgram(model{})
-
-	// Run returns the model as a tea.Model.
-	m, err := p.Run()
+	// RunModel returns the final model as our local model.
+	m, err := tea.RunModel(model{})
 	if err != nil {
 		fmt.Println("Oh no:", err)
 		os.Exit(1)
 	}
 
-	// Assert the final tea.Model to our local model and print the choice.
-	if m, ok := m.(model); ok && m.choice != "" {
+	if m.choice != "" {
 		fmt.Printf("\n---\nYou chose %s!\n", m.choice)
 	}
 }
@@ -0,0 +1,141 @@
+package tea
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// FileDropMsg is sent when files are dragged and dropped onto the terminal.
+// Most terminals paste the shell-escaped paths of the dropped files, or their
+// file:// URIs, and Bubble Tea recognizes them in the paste. Paths are
+// absolute and exist at the time of the drop. It requires [WithFileDrops].
+type FileDropMsg struct {
+	Paths []string
+}
+
+// String returns the dropped paths separated by spaces.
+func (m FileDropMsg) String() string {
+	return strings.Join(m.Paths, " ")
+}
+
+// parseFileDrop recognizes a paste made of dropped files. It reports whether
+// the paste is only made of absolute paths, or file:// URIs, of existing
+// files.
+func parseFileDrop(content string) ([]string, bool) {
+	var paths []string
+	if uris, ok := parseURIList(content); ok {
+		paths = uris
+	} else if words, ok := splitShellWords(content); ok {
+		for _, word := range words {
+			if path, ok := fileURIPath(word); ok {
+				word = path
+			}
+			paths = append(paths, word)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, false
+	}
+
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			return nil, false
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, false
+		}
+	}
+	return paths, true
+}
+
+// parseURIList parses a text/uri-list of file:// URIs, one per line. Lines
+// starting with "#" are comments.
+func parseURIList(content string) ([]string, bool) {
+	var paths []string
+	for line := range strings.Lines(content) {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		path, ok := fileURIPath(line)
+		if !ok {
+			return nil, false
+		}
+		paths = append(paths, path)
+	}
+	return paths, len(paths) > 0
+}
+
+// fileURIPath returns the local path of a file:// URI.
+func fileURIPath(s string) (string, bool) {
+	if !strings.HasPrefix(s, "file://") {
+		return "", false
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Host != "" && u.Host != "localhost") {
+		return "", false
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		// file:///C:/path
+		path = filepath.FromSlash(strings.TrimPrefix(path, "/"))
+	}
+	return path, path != ""
+}
+
+// splitShellWords splits a string into words the way a POSIX shell does,
+// handling single quotes, double quotes, and backslash escapes. Backslashes
+// are path separators on Windows and are kept as is there. It reports false
+// on unterminated quotes.
+func splitShellWords(s string) ([]string, bool) {
+	escapes := runtime.GOOS != "windows"
+
+	var (
+		words  []string
+		word   strings.Builder
+		inWord bool
+		quote  rune
+		escape bool
+	)
+	for _, r := range s {
+		switch {
+		case escape:
+			escape = false
+			if quote == '"' && !strings.ContainsRune("\"\\$`\n", r) {
+				word.WriteRune('\\')
+			}
+			if r != '\n' {
+				word.WriteRune(r)
+			}
+		case r == '\\' && escapes && quote != '\'':
+			escape, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escape {
+		return nil, false
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, true
+}
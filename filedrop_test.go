@@ -0,0 +1,148 @@
+package tea
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestParseFileDrop(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("shell escaped paths aren't used on Windows")
+	}
+
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "notes.txt")
+	spaced := filepath.Join(dir, "my file's.txt")
+	for _, path := range []string{plain, spaced} {
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		content string
+		paths   []string
+	}{
+		{
+			name:    "plain",
+			content: plain + " ",
+			paths:   []string{plain},
+		},
+		{
+			name:    "backslash escaped",
+			content: plain + ` ` + dir + `/my\ file\'s.txt`,
+			paths:   []string{plain, spaced},
+		},
+		{
+			name:    "quoted",
+			content: `'` + dir + `/my file'\''s.txt' "` + plain + `"`,
+			paths:   []string{spaced, plain},
+		},
+		{
+			name:    "uri list",
+			content: "# dropped\r\nfile://" + dir + "/my%20file's.txt\r\nfile://localhost" + plain + "\r\n",
+			paths:   []string{spaced, plain},
+		},
+		{
+			name:    "missing file",
+			content: plain + " " + filepath.Join(dir, "missing"),
+		},
+		{
+			name:    "relative path",
+			content: "notes.txt",
+		},
+		{
+			name:    "text",
+			content: "hello world",
+		},
+		{
+			name:    "unterminated quote",
+			content: `'` + plain,
+		},
+		{
+			name:    "remote uri",
+			content: "file://example.com" + plain,
+		},
+		{
+			name:    "empty",
+			content: " ",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			paths, ok := parseFileDrop(tc.content)
+			if ok != (tc.paths != nil) {
+				t.Fatalf("expected drop to be %v, got %v", tc.paths != nil, ok)
+			}
+			if !reflect.DeepEqual(paths, tc.paths) {
+				t.Errorf("expected paths %q, got %q", tc.paths, paths)
+			}
+		})
+	}
+}
+
+func TestFileDrops(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pr, pw := io.Pipe()
+	defer pw.Close() //nolint:errcheck
+
+	m := fileDropModel{msgs: make(chan Msg, 10)}
+	p := NewProgram(m,
+		WithContext(t.Context()),
+		WithInput(pr),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+		WithFileDrops(),
+	)
+
+	go func() {
+		_, _ = io.WriteString(pw, "\x1b[200~"+path+"\x1b[201~\x1b[200~hello\x1b[201~q")
+	}()
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	close(m.msgs)
+
+	var got []Msg
+	for msg := range m.msgs {
+		got = append(got, msg)
+	}
+	want := []Msg{FileDropMsg{Paths: []string{path}}, PasteMsg{Content: "hello"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+type fileDropModel struct {
+	msgs chan Msg
+}
+
+func (m fileDropModel) Init() Cmd { return nil }
+
+func (m fileDropModel) Update(msg Msg) (Model, Cmd) {
+	switch msg := msg.(type) {
+	case FileDropMsg, PasteMsg:
+		m.msgs <- msg
+	case KeyPressMsg:
+		if msg.String() == "q" {
+			return m, Quit
+		}
+	}
+	return m, nil
+}
+
+func (m fileDropModel) View() View { return NewView("drop") }
@@ -0,0 +1,92 @@
+package tea
+
+// FuncModel is a [Model] built from functions over a state, for programs that
+// don't need a type of their own. Create one with [NewFuncModel] or
+// [NewProgramFunc].
+//
+// The state is handled with value semantics: every update works on a copy of
+// the state and returns a new FuncModel, so earlier models keep their state.
+// The exception is Init, which applies init to the model in place, so a
+// FuncModel should only be run once.
+type FuncModel[S any] struct {
+	state  S
+	init   func(S) (S, Cmd)
+	update func(S, Msg) (S, Cmd)
+	view   func(S) View
+}
+
+// NewFuncModel returns a model that calls init when the program starts,
+// update for every message, and view to render the state. init and update
+// can be nil.
+func NewFuncModel[S any](
+	state S,
+	init func(S) (S, Cmd),
+	update func(S, Msg) (S, Cmd),
+	view func(S) View,
+) *FuncModel[S] {
+	return &FuncModel[S]{state: state, init: init, update: update, view: view}
+}
+
+// NewProgramFunc returns a program that runs a [FuncModel] with the given
+// state and functions. It accepts the same options as [NewProgram].
+//
+//	p := tea.NewProgramFunc(0,
+//	    nil,
+//	    func(n int, msg tea.Msg) (int, tea.Cmd) {
+//	        if _, ok := msg.(tea.KeyPressMsg); ok {
+//	            return n + 1, nil
+//	        }
+//	        return n, nil
+//	    },
+//	    func(n int) tea.View {
+//	        return tea.NewView(fmt.Sprintf("%d keys pressed", n))
+//	    },
+//	)
+//
+// The final state is available with [FuncModel.State] on the model returned
+// by [Program.Run].
+func NewProgramFunc[S any](
+	state S,
+	init func(S) (S, Cmd),
+	update func(S, Msg) (S, Cmd),
+	view func(S) View,
+	opts ...ProgramOption,
+) *Program {
+	return NewProgram(NewFuncModel(state, init, update, view), opts...)
+}
+
+// State returns the state of the model.
+func (m *FuncModel[S]) State() S {
+	return m.state
+}
+
+// Init implements [Model]. It applies init to the initial state, replacing
+// the state of m.
+func (m *FuncModel[S]) Init() Cmd {
+	if m.init == nil {
+		return nil
+	}
+	var cmd Cmd
+	m.state, cmd = m.init(m.state)
+	return cmd
+}
+
+// Update implements [Model]. It returns a new model holding the updated
+// state.
+func (m *FuncModel[S]) Update(msg Msg) (Model, Cmd) {
+	if m.update == nil {
+		return m, nil
+	}
+	next := *m
+	var cmd Cmd
+	next.state, cmd = m.update(m.state, msg)
+	return &next, cmd
+}
+
+// View implements [Model].
+func (m *FuncModel[S]) View() View {
+	if m.view == nil {
+		return View{}
+	}
+	return m.view(m.state)
+}
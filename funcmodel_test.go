@@ -0,0 +1,78 @@
+package tea
+
+import (
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestNewProgramFunc(t *testing.T) {
+	t.Parallel()
+
+	type state struct {
+		words []string
+	}
+
+	var seen []*FuncModel[state]
+	p := NewProgramFunc(state{words: []string{"hello"}},
+		func(s state) (state, Cmd) {
+			s.words = append(slices.Clone(s.words), "init")
+			return s, func() Msg { return "world" }
+		},
+		func(s state, msg Msg) (state, Cmd) {
+			if word, ok := msg.(string); ok {
+				s.words = append(slices.Clone(s.words), word)
+				return s, Quit
+			}
+			return s, nil
+		},
+		func(s state) View {
+			return NewView(s.words[len(s.words)-1])
+		},
+		WithContext(t.Context()),
+		WithInput(nil),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+		WithFilter(func(m Model, msg Msg) Msg {
+			seen = append(seen, m.(*FuncModel[state]))
+			return msg
+		}),
+	)
+
+	final, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := final.(*FuncModel[state]).State().words, []string{"hello", "init", "world"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// Earlier models keep their state.
+	if got, want := seen[0].State().words, []string{"hello", "init"}; !slices.Equal(got, want) {
+		t.Errorf("expected the first model to hold %v, got %v", want, got)
+	}
+}
+
+func TestRunModelFunc(t *testing.T) {
+	t.Parallel()
+
+	n, err := RunModelResult(
+		NewFuncModel(1, nil, func(n int, msg Msg) (int, Cmd) {
+			if _, ok := msg.(WindowSizeMsg); ok {
+				return n * 2, Quit
+			}
+			return n, nil
+		}, func(int) View { return View{} }),
+		(*FuncModel[int]).State,
+		WithContext(t.Context()),
+		WithInput(nil),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2, got %d", n)
+	}
+}
@@ -0,0 +1,244 @@
+package tea
+
+import (
+	"time"
+)
+
+const (
+	// defaultClickInterval is the default maximum amount of time between the
+	// clicks of a double or triple click.
+	defaultClickInterval = 500 * time.Millisecond
+
+	// defaultDragThreshold is the default distance, in cells, the pointer
+	// has to travel with a button held down to start a drag.
+	defaultDragThreshold = 1
+
+	// defaultHoverDelay is the default amount of time the pointer has to rest
+	// to hover.
+	defaultHoverDelay = 500 * time.Millisecond
+)
+
+// MouseGestures configures the mouse gestures derived from the raw mouse
+// messages. Zero values use the defaults. Use [WithMouseGestures] to enable
+// mouse gestures in a program.
+type MouseGestures struct {
+	// ClickInterval is the maximum amount of time between two clicks of a
+	// double or triple click. It defaults to 500ms.
+	ClickInterval time.Duration
+
+	// ClickDistance is the maximum distance, in cells, between two clicks of
+	// a double or triple click. It defaults to 0, meaning the clicks must
+	// happen on the same cell.
+	ClickDistance int
+
+	// DragThreshold is the distance, in cells, the pointer has to travel
+	// with a button held down before a drag starts. It defaults to 1.
+	DragThreshold int
+
+	// HoverDelay is the amount of time the pointer has to rest before it
+	// hovers. It defaults to 500ms. A negative delay disables hover
+	// messages. Hovering requires [MouseModeAllMotion].
+	HoverDelay time.Duration
+}
+
+// MouseDoubleClickMsg is sent after the [MouseClickMsg] of the second click
+// of a double click. It requires [WithMouseGestures].
+type MouseDoubleClickMsg Mouse
+
+// String returns a string representation of the mouse double click message.
+func (e MouseDoubleClickMsg) String() string {
+	return Mouse(e).String() + "+double"
+}
+
+// Mouse returns the underlying mouse event. This is a convenience method and
+// syntactic sugar to satisfy the [MouseMsg] interface, and cast the mouse
+// event to [Mouse].
+func (e MouseDoubleClickMsg) Mouse() Mouse {
+	return Mouse(e)
+}
+
+// MouseTripleClickMsg is sent after the [MouseClickMsg] of the third click
+// of a triple click. It requires [WithMouseGestures].
+type MouseTripleClickMsg Mouse
+
+// String returns a string representation of the mouse triple click message.
+func (e MouseTripleClickMsg) String() string {
+	return Mouse(e).String() + "+triple"
+}
+
+// Mouse returns the underlying mouse event. This is a convenience method and
+// syntactic sugar to satisfy the [MouseMsg] interface, and cast the mouse
+// event to [Mouse].
+func (e MouseTripleClickMsg) Mouse() Mouse {
+	return Mouse(e)
+}
+
+// MouseDrag describes a drag gesture.
+type MouseDrag struct {
+	// Start is where the button was pressed.
+	Start Mouse
+
+	// Current is the current position of the pointer.
+	Current Mouse
+}
+
+// MouseDragStartMsg is sent when the pointer travels further than the drag
+// threshold with a button held down. It requires [WithMouseGestures].
+type MouseDragStartMsg MouseDrag
+
+// MouseDragMsg is sent when the pointer moves during a drag. It requires
+// [WithMouseGestures].
+type MouseDragMsg MouseDrag
+
+// MouseDragEndMsg is sent when the button is released at the end of a drag.
+// It requires [WithMouseGestures].
+type MouseDragEndMsg MouseDrag
+
+// MouseHoverEnterMsg is sent when the pointer rests on a cell for the hover
+// delay. It requires [WithMouseGestures] and [MouseModeAllMotion].
+type MouseHoverEnterMsg Mouse
+
+// MouseHoverLeaveMsg is sent when the pointer leaves the cell it hovered, or
+// a button is pressed. It holds the position of the hovered cell. It
+// requires [WithMouseGestures] and [MouseModeAllMotion].
+type MouseHoverLeaveMsg Mouse
+
+// mouseHoverMsg is an internal message that signals that the pointer rested
+// for the hover delay. It holds the generation of the pointer motion so that
+// stale timers can be ignored.
+type mouseHoverMsg int
+
+// gestureTracker derives mouse gestures from raw mouse messages. It's only
+// accessed from the event loop.
+type gestureTracker struct {
+	MouseGestures
+
+	// Multi-click state.
+	lastClick     Mouse
+	lastClickTime time.Time
+	clicks        int
+
+	// Drag state.
+	pressed  bool
+	dragging bool
+	start    Mouse
+
+	// Hover state.
+	clock      Clock
+	hoverGen   int
+	hoverTimer Timer
+	hovering   bool
+	pointer    Mouse
+}
+
+// newGestureTracker returns a gesture tracker with the given configuration,
+// filling in the defaults.
+func newGestureTracker(g MouseGestures) *gestureTracker {
+	if g.ClickInterval <= 0 {
+		g.ClickInterval = defaultClickInterval
+	}
+	if g.DragThreshold <= 0 {
+		g.DragThreshold = defaultDragThreshold
+	}
+	if g.HoverDelay == 0 {
+		g.HoverDelay = defaultHoverDelay
+	}
+	return &gestureTracker{MouseGestures: g, clock: systemClock{}}
+}
+
+// mouseDistance returns the distance, in cells, between two mouse positions.
+func mouseDistance(a, b Mouse) int {
+	return max(abs(a.X-b.X), abs(a.Y-b.Y))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// update feeds msg to the tracker and returns the gesture messages it
+// derives, if any. send is used to deliver hover messages from the hover
+// timer.
+func (g *gestureTracker) update(msg Msg, now time.Time, send func(Msg)) []Msg {
+	var out []Msg
+	switch msg := msg.(type) {
+	case MouseClickMsg:
+		m := Mouse(msg)
+		out = g.leave(out)
+
+		if g.clicks > 0 && g.clicks < 3 && m.Button == g.lastClick.Button &&
+			now.Sub(g.lastClickTime) <= g.ClickInterval &&
+			mouseDistance(m, g.lastClick) <= g.ClickDistance {
+			g.clicks++
+		} else {
+			g.clicks = 1
+		}
+		g.lastClick, g.lastClickTime = m, now
+		switch g.clicks {
+		case 2:
+			out = append(out, MouseDoubleClickMsg(m))
+		case 3:
+			out = append(out, MouseTripleClickMsg(m))
+		}
+
+		g.pressed, g.dragging, g.start = true, false, m
+
+	case MouseMotionMsg:
+		m := Mouse(msg)
+		if g.pressed {
+			drag := MouseDrag{Start: g.start, Current: m}
+			switch {
+			case g.dragging:
+				out = append(out, MouseDragMsg(drag))
+			case mouseDistance(m, g.start) >= g.DragThreshold:
+				// Dragging cancels multi-clicks.
+				g.dragging, g.clicks = true, 0
+				out = append(out, MouseDragStartMsg(drag))
+			}
+			break
+		}
+
+		if g.hovering && mouseDistance(m, g.pointer) > 0 {
+			out = g.leave(out)
+		}
+		if !g.hovering && g.HoverDelay > 0 {
+			g.pointer = m
+			g.hoverGen++
+			gen := g.hoverGen
+			if g.hoverTimer != nil {
+				g.hoverTimer.Stop()
+			}
+			g.hoverTimer = g.clock.AfterFunc(g.HoverDelay, func() {
+				send(mouseHoverMsg(gen))
+			})
+		}
+
+	case MouseReleaseMsg:
+		if g.dragging {
+			out = append(out, MouseDragEndMsg(MouseDrag{Start: g.start, Current: Mouse(msg)}))
+		}
+		g.pressed, g.dragging = false, false
+
+	case mouseHoverMsg:
+		if int(msg) == g.hoverGen && !g.pressed && !g.hovering {
+			g.hovering = true
+			out = append(out, MouseHoverEnterMsg(g.pointer))
+		}
+	}
+	return out
+}
+
+// leave ends the current hover, if any, and cancels the pending one.
+func (g *gestureTracker) leave(out []Msg) []Msg {
+	g.hoverGen++
+	if g.hoverTimer != nil {
+		g.hoverTimer.Stop()
+	}
+	if g.hovering {
+		g.hovering = false
+		out = append(out, MouseHoverLeaveMsg(g.pointer))
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package tea
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGestureTracker(t *testing.T) {
+	t.Parallel()
+
+	noop := func(Msg) {}
+	now := time.Now()
+
+	t.Run("clicks", func(t *testing.T) {
+		t.Parallel()
+		g := newGestureTracker(MouseGestures{HoverDelay: -1})
+		click := MouseClickMsg{X: 1, Y: 1, Button: MouseLeft}
+
+		var got []Msg
+		for i := range 4 {
+			got = append(got, g.update(click, now.Add(time.Duration(i)*100*time.Millisecond), noop)...)
+			g.update(MouseReleaseMsg(click), now, noop)
+		}
+		// Too late for a double click.
+		got = append(got, g.update(click, now.Add(2*time.Second), noop)...)
+		// Another button.
+		got = append(got, g.update(MouseClickMsg{X: 1, Y: 1, Button: MouseRight}, now.Add(2*time.Second), noop)...)
+
+		want := []Msg{MouseDoubleClickMsg(click), MouseTripleClickMsg(click)}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("drag", func(t *testing.T) {
+		t.Parallel()
+		g := newGestureTracker(MouseGestures{DragThreshold: 2, HoverDelay: -1})
+		start := Mouse{X: 1, Y: 1, Button: MouseLeft}
+
+		var got []Msg
+		for _, msg := range []Msg{
+			MouseClickMsg(start),
+			MouseMotionMsg{X: 2, Y: 1, Button: MouseLeft}, // below the threshold
+			MouseMotionMsg{X: 3, Y: 1, Button: MouseLeft},
+			MouseMotionMsg{X: 4, Y: 2, Button: MouseLeft},
+			MouseReleaseMsg{X: 4, Y: 2, Button: MouseLeft},
+		} {
+			got = append(got, g.update(msg, now, noop)...)
+		}
+
+		want := []Msg{
+			MouseDragStartMsg{Start: start, Current: Mouse{X: 3, Y: 1, Button: MouseLeft}},
+			MouseDragMsg{Start: start, Current: Mouse{X: 4, Y: 2, Button: MouseLeft}},
+			MouseDragEndMsg{Start: start, Current: Mouse{X: 4, Y: 2, Button: MouseLeft}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("hover", func(t *testing.T) {
+		t.Parallel()
+		g := newGestureTracker(MouseGestures{HoverDelay: time.Millisecond})
+		hovers := make(chan Msg, 1)
+
+		g.update(MouseMotionMsg{X: 5, Y: 5}, now, func(msg Msg) { hovers <- msg })
+		got := g.update(<-hovers, now, noop)
+		if want := []Msg{MouseHoverEnterMsg{X: 5, Y: 5}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+
+		// Stale timers are ignored.
+		if got := g.update(mouseHoverMsg(0), now, noop); len(got) != 0 {
+			t.Errorf("expected no messages, got %v", got)
+		}
+
+		got = g.update(MouseMotionMsg{X: 6, Y: 5}, now, func(Msg) {})
+		if want := []Msg{MouseHoverLeaveMsg{X: 5, Y: 5}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	})
+}
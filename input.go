@@ -21,26 +21,42 @@ func (p *Program) translateInputEvent(e uv.Event) Msg {
 		return FocusMsg(e)
 	case uv.BlurEvent:
 		return BlurMsg(e)
+	case uv.DarkColorSchemeEvent:
+		return ColorSchemeMsg{Dark: true}
+	case uv.LightColorSchemeEvent:
+		return ColorSchemeMsg{Dark: false}
 	case uv.KeyPressEvent:
 		return KeyPressMsg(e)
 	case uv.KeyReleaseEvent:
 		return KeyReleaseMsg(e)
 	case uv.MouseClickEvent:
-		return MouseClickMsg(e)
+		return MouseClickMsg(p.mouse(uv.Mouse(e)))
 	case uv.MouseMotionEvent:
-		return MouseMotionMsg(e)
+		return MouseMotionMsg(p.mouse(uv.Mouse(e)))
 	case uv.MouseReleaseEvent:
-		return MouseReleaseMsg(e)
+		return MouseReleaseMsg(p.mouse(uv.Mouse(e)))
 	case uv.MouseWheelEvent:
-		return MouseWheelMsg(e)
+		m := p.mouse(uv.Mouse(e))
+		m.Delta = 1
+		return MouseWheelMsg(m)
+	case mouseWheelEvent:
+		m := p.mouse(uv.Mouse(e.MouseWheelEvent))
+		m.Delta = e.delta
+		return MouseWheelMsg(m)
 	case uv.PasteEvent:
 		return PasteMsg(e)
 	case uv.PasteStartEvent:
 		return PasteStartMsg(e)
 	case uv.PasteEndEvent:
-		return PasteEndMsg(e)
+		return PasteEndMsg{}
+	case uv.UnknownApcEvent:
+		if e == pasteMarker && p.pasteMsgs != nil {
+			return pasteMarkerMsg{}
+		}
 	case uv.WindowSizeEvent:
 		return WindowSizeMsg(e)
+	case uv.CellSizeEvent:
+		return CellSizeMsg(e)
 	case uv.CapabilityEvent:
 		return CapabilityMsg(e)
 	case uv.TerminalVersionEvent:
@@ -49,6 +65,14 @@ func (p *Program) translateInputEvent(e uv.Event) Msg {
 		return KeyboardEnhancementsMsg(e)
 	case uv.ModeReportEvent:
 		return ModeReportMsg(e)
+	case uv.PrimaryDeviceAttributesEvent:
+		return PrimaryDeviceAttributesMsg(e)
+	case uv.SecondaryDeviceAttributesEvent:
+		return SecondaryDeviceAttributesMsg(e)
+	case uv.UnknownOscEvent:
+		if msg, ok := parsePaletteColor(string(e)); ok {
+			return msg
+		}
 	}
 	return e
 }
@@ -0,0 +1,233 @@
+package tea
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultKeymapTimeout is the default amount of time a [Keymap] waits for the
+// next key of a chord.
+const defaultKeymapTimeout = time.Second
+
+// Binding binds one or more key sequences to an action.
+type Binding struct {
+	// Action identifies the binding. It's what your program matches on when
+	// it receives a [BindingMsg].
+	Action string
+
+	// Keys are the key sequences that trigger the binding. A sequence is a
+	// list of space separated keystrokes, as returned by [Key.Keystroke] or
+	// [Key.String]. For example, "ctrl+s", "g g", or "ctrl+x ctrl+s".
+	Keys []string
+
+	// Help is a short description of the action, used in help views.
+	Help string
+}
+
+// BindingMsg is sent when the user typed one of the key sequences of a
+// [Binding] registered with [WithKeymap]. The key presses that make up the
+// sequence are not sent to the program.
+//
+// Example:
+//
+//	func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+//	  switch msg := msg.(type) {
+//	  case tea.BindingMsg:
+//	    switch msg.Action {
+//	    case "save":
+//	      return m, m.save
+//	    case "quit":
+//	      return m, tea.Quit
+//	    }
+//	  }
+//	  return m, nil
+//	}
+type BindingMsg struct {
+	Binding
+
+	// Keys are the key presses that triggered the binding.
+	Keys []KeyPressMsg
+}
+
+// keymapTimeoutMsg is an internal message that signals that the keymap gave
+// up waiting for the next key of a chord. It holds the generation of the
+// pending chord so that stale timeouts can be ignored.
+type keymapTimeoutMsg int
+
+// Keymap matches key presses against a set of [Binding]s. Bindings can span
+// several keys, such as "g g" or "ctrl+x ctrl+s". While a chord is pending,
+// the keymap holds on to the keys typed so far, up to its timeout. If the
+// chord isn't completed in time, or the next key doesn't continue it, the
+// pending keys are dropped, unless they form a binding of their own.
+//
+// Key presses that aren't part of any binding are sent to the program as
+// usual. Use [WithKeymap] to install a keymap in a program.
+//
+// A Keymap is safe for concurrent use, so your model can change the bindings
+// or query them to render help views.
+type Keymap struct {
+	mu       sync.Mutex
+	bindings []Binding
+	timeout  time.Duration
+	pending  []KeyPressMsg
+	gen      int
+}
+
+// NewKeymap returns a new [Keymap] with the given bindings and the default
+// chord timeout of 1 second.
+func NewKeymap(bindings ...Binding) *Keymap {
+	return &Keymap{
+		bindings: bindings,
+		timeout:  defaultKeymapTimeout,
+	}
+}
+
+// SetTimeout sets how long the keymap waits for the next key of a chord. A
+// timeout less than 1 waits forever.
+func (k *Keymap) SetTimeout(d time.Duration) {
+	k.mu.Lock()
+	k.timeout = d
+	k.mu.Unlock()
+}
+
+// SetBindings replaces the bindings of the keymap and drops any pending
+// chord.
+func (k *Keymap) SetBindings(bindings ...Binding) {
+	k.mu.Lock()
+	k.bindings = bindings
+	k.pending = nil
+	k.gen++
+	k.mu.Unlock()
+}
+
+// Bindings returns all the bindings of the keymap.
+func (k *Keymap) Bindings() []Binding {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return slices.Clone(k.bindings)
+}
+
+// Pending returns the keys of the chord being typed, if any.
+func (k *Keymap) Pending() []KeyPressMsg {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return slices.Clone(k.pending)
+}
+
+// Active returns the bindings that can still be triggered given the keys
+// typed so far. When no chord is pending, it returns all the bindings. This
+// is useful to show the user what they can type next.
+func (k *Keymap) Active() []Binding {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.pending) == 0 {
+		return slices.Clone(k.bindings)
+	}
+	var active []Binding
+	for _, b := range k.bindings {
+		if slices.ContainsFunc(b.Keys, func(seq string) bool {
+			return keysHavePrefix(strings.Fields(seq), k.pending)
+		}) {
+			active = append(active, b)
+		}
+	}
+	return active
+}
+
+// resolve feeds msg to the keymap. It returns the message that should be
+// handled in its place, which is nil if the keymap swallowed it, and a key
+// that must be resolved again because it broke a pending chord. wait is set
+// when a new chord is pending and the keymap waits for the next key.
+func (k *Keymap) resolve(msg Msg) (out Msg, replay Msg, wait time.Duration, gen int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	switch msg := msg.(type) {
+	case KeyPressMsg:
+		keys := append(slices.Clone(k.pending), msg)
+		exact, longer := k.lookup(keys)
+		switch {
+		case longer:
+			k.pending = keys
+			k.gen++
+			return nil, nil, k.timeout, k.gen
+		case exact != nil:
+			k.pending = nil
+			return BindingMsg{Binding: *exact, Keys: keys}, nil, 0, 0
+		case len(k.pending) > 0:
+			// The key doesn't continue the chord. Resolve the chord typed so
+			// far, and then the key on its own.
+			return k.flush(), msg, 0, 0
+		}
+	case keymapTimeoutMsg:
+		if int(msg) != k.gen || len(k.pending) == 0 {
+			return nil, nil, 0, 0
+		}
+		return k.flush(), nil, 0, 0
+	}
+
+	return msg, nil, 0, 0
+}
+
+// flush drops the pending chord. It returns a [BindingMsg] if the chord
+// matches a binding, or nil otherwise.
+func (k *Keymap) flush() Msg {
+	keys := k.pending
+	k.pending = nil
+	k.gen++
+	if exact, _ := k.lookup(keys); exact != nil {
+		return BindingMsg{Binding: *exact, Keys: keys}
+	}
+	return nil
+}
+
+// lookup returns the first binding that matches keys exactly, if any, and
+// whether there are longer key sequences that start with keys.
+func (k *Keymap) lookup(keys []KeyPressMsg) (exact *Binding, longer bool) {
+	for i, b := range k.bindings {
+		for _, seq := range b.Keys {
+			fields := strings.Fields(seq)
+			if !keysHavePrefix(fields, keys) {
+				continue
+			}
+			if len(fields) > len(keys) {
+				longer = true
+			} else if exact == nil {
+				exact = &k.bindings[i]
+			}
+		}
+	}
+	return exact, longer
+}
+
+// keysHavePrefix returns whether the keystrokes in seq start with keys.
+func keysHavePrefix(seq []string, keys []KeyPressMsg) bool {
+	if len(seq) < len(keys) {
+		return false
+	}
+	for i, key := range keys {
+		if seq[i] != key.Keystroke() && seq[i] != key.String() {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveBinding resolves msg against the program's keymap and arms the
+// chord timeout when the keymap starts waiting for the next key.
+func (p *Program) resolveBinding(msg Msg) (Msg, Msg) {
+	out, replay, wait, gen := p.keymap.resolve(msg)
+	if gen > 0 {
+		if p.keymapTimer != nil {
+			p.keymapTimer.Stop()
+		}
+		if wait > 0 {
+			p.keymapTimer = p.clock.AfterFunc(wait, func() {
+				p.Send(keymapTimeoutMsg(gen))
+			})
+		}
+	}
+	return out, replay
+}
@@ -0,0 +1,328 @@
+package tea
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// KeymapError describes a problem found while loading a keymap file.
+type KeymapError struct {
+	// File is the name of the keymap file, if any.
+	File string
+
+	// Line and Column are the 1-based position of the problem in the file.
+	Line, Column int
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (e *KeymapError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %v", e.File, e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *KeymapError) Unwrap() error {
+	return e.Err
+}
+
+// LoadKeymap reads the keymap file at path. See [ReadKeymap] for details.
+func LoadKeymap(path string, defaults ...Binding) ([]Binding, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("bubbletea: error opening keymap: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+	return ReadKeymap(path, f, defaults...)
+}
+
+// ReadKeymap reads a keymap from r and applies it to the default bindings.
+// It returns the resulting bindings, ready to be used with [NewKeymap]. The
+// name is used to report errors.
+//
+// The keymap lists the key sequences of each action. Every action must be one
+// of the actions of the default bindings, and an action that's listed
+// replaces all the default keys of that action. Actions that aren't listed
+// keep their default keys.
+//
+// The keymap is either a JSON object that maps actions to a key sequence or a
+// list of key sequences:
+//
+//	{
+//	  "save": ["ctrl+s", "ctrl+x ctrl+s"],
+//	  "quit": "q"
+//	}
+//
+// Or a simple INI-like file with one "action = key sequence" per line. An
+// action can be listed several times to bind several key sequences, or with
+// an empty key sequence to unbind it. Lines starting with "#" or ";" are
+// comments:
+//
+//	# Emacs style saving.
+//	save = ctrl+s
+//	save = ctrl+x ctrl+s
+//	quit = q
+//
+// Each keystroke of a key sequence is validated with [ParseKeystroke] and
+// written in its canonical form, so that "A" becomes "shift+a". Binding the
+// same key sequence to two actions is a conflict.
+//
+// When the keymap is invalid, the returned error joins a [*KeymapError] for
+// every problem found.
+func ReadKeymap(name string, r io.Reader, defaults ...Binding) ([]Binding, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("bubbletea: error reading keymap: %w", err)
+	}
+
+	l := keymapLoader{
+		name:    name,
+		data:    data,
+		actions: make(map[string]bool, len(defaults)),
+		entries: make(map[string][]keymapEntry),
+	}
+	for _, b := range defaults {
+		l.actions[b.Action] = true
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		l.parseJSON()
+	} else {
+		l.parseINI()
+	}
+
+	bindings := make([]Binding, 0, len(defaults))
+	for _, b := range defaults {
+		if entries, ok := l.entries[b.Action]; ok {
+			b.Keys = make([]string, 0, len(entries))
+			for _, e := range entries {
+				b.Keys = append(b.Keys, e.keys)
+			}
+		}
+		bindings = append(bindings, b)
+	}
+	l.checkConflicts(bindings)
+
+	if len(l.errs) > 0 {
+		return nil, errors.Join(l.errs...)
+	}
+	return bindings, nil
+}
+
+// keymapEntry is a key sequence read from a keymap file, along with its
+// position.
+type keymapEntry struct {
+	keys   string
+	offset int
+}
+
+// keymapLoader holds the state of [ReadKeymap].
+type keymapLoader struct {
+	name    string
+	data    []byte
+	actions map[string]bool
+	entries map[string][]keymapEntry
+	order   []string
+	errs    []error
+}
+
+// errorf records an error at the given byte offset of the file.
+func (l *keymapLoader) errorf(offset int, format string, args ...any) {
+	line, col := 1, 1
+	prefix := l.data[:min(offset, len(l.data))]
+	if i := bytes.LastIndexByte(prefix, '\n'); i >= 0 {
+		line += bytes.Count(prefix, []byte{'\n'})
+		prefix = prefix[i+1:]
+	}
+	col += utf8.RuneCount(prefix)
+	l.errs = append(l.errs, &KeymapError{
+		File:   l.name,
+		Line:   line,
+		Column: col,
+		Err:    fmt.Errorf(format, args...),
+	})
+}
+
+// action records that the action at the given offset is listed in the file.
+// It reports whether the action is known.
+func (l *keymapLoader) action(action string, offset int) bool {
+	if !l.actions[action] {
+		l.errorf(offset, "unknown action %q", action)
+		return false
+	}
+	if _, ok := l.entries[action]; !ok {
+		l.entries[action] = nil
+		l.order = append(l.order, action)
+	}
+	return true
+}
+
+// bind validates the key sequence at the given offset and binds it to the
+// action. An empty key sequence only marks the action as listed.
+func (l *keymapLoader) bind(action, seq string, offset int) {
+	var keystrokes []string
+	rest := seq
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			break
+		}
+		keystroke, next := rest, ""
+		if i := strings.IndexAny(rest, " \t"); i >= 0 {
+			keystroke, next = rest[:i], rest[i:]
+		}
+		k, err := ParseKeystroke(keystroke)
+		if err != nil {
+			l.errorf(offset+len(seq)-len(rest), "invalid key sequence %q: %w", seq, err)
+			return
+		}
+		keystrokes = append(keystrokes, k.Keystroke())
+		rest = next
+	}
+	if len(keystrokes) == 0 {
+		return
+	}
+	l.entries[action] = append(l.entries[action], keymapEntry{
+		keys:   strings.Join(keystrokes, " "),
+		offset: offset,
+	})
+}
+
+// parseINI parses an INI-like keymap with one "action = keys" per line.
+// Lines end with LF or CRLF.
+func (l *keymapLoader) parseINI() {
+	data := string(l.data)
+	for start, next := 0, 0; start < len(data); start = next {
+		line, _, _ := strings.Cut(data[start:], "\n")
+		next = start + len(line) + 1
+		line = strings.TrimSuffix(line, "\r")
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == ';' {
+			continue
+		}
+		action, seq, ok := strings.Cut(line, "=")
+		if !ok {
+			l.errorf(start+len(line)-len(strings.TrimLeft(line, " \t")), "expected \"action = keys\", got %q", trimmed)
+			continue
+		}
+		actionOffset := start + len(action) - len(strings.TrimLeft(action, " \t"))
+		if !l.action(strings.TrimSpace(action), actionOffset) {
+			continue
+		}
+		seqOffset := start + len(action) + 1
+		seqOffset += len(seq) - len(strings.TrimLeft(seq, " \t"))
+		l.bind(strings.TrimSpace(action), strings.TrimSpace(seq), seqOffset)
+	}
+}
+
+// parseJSON parses a JSON keymap that maps actions to key sequences.
+func (l *keymapLoader) parseJSON() {
+	dec := json.NewDecoder(bytes.NewReader(l.data))
+
+	// next returns the next token along with its offset.
+	next := func() (json.Token, int, bool) {
+		offset := int(dec.InputOffset())
+		for offset < len(l.data) && strings.IndexByte(" \t\r\n:,", l.data[offset]) >= 0 {
+			offset++
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			var serr *json.SyntaxError
+			if errors.As(err, &serr) {
+				offset = int(serr.Offset)
+			}
+			l.errorf(offset, "%v", err)
+			return nil, offset, false
+		}
+		return tok, offset, true
+	}
+
+	if tok, offset, ok := next(); !ok {
+		return
+	} else if tok != json.Delim('{') {
+		l.errorf(offset, "expected an object of actions")
+		return
+	}
+
+	for dec.More() {
+		tok, offset, ok := next()
+		if !ok {
+			return
+		}
+		action, _ := tok.(string)
+		known := l.action(action, offset)
+
+		tok, offset, ok = next()
+		if !ok {
+			return
+		}
+		switch tok := tok.(type) {
+		case string:
+			if known {
+				l.bind(action, tok, offset+1)
+			}
+		case json.Delim:
+			if tok != '[' {
+				l.errorf(offset, "expected a key sequence or a list of key sequences")
+				return
+			}
+			for dec.More() {
+				tok, offset, ok := next()
+				if !ok {
+					return
+				}
+				seq, isString := tok.(string)
+				if !isString {
+					l.errorf(offset, "expected a key sequence")
+					return
+				}
+				if known {
+					l.bind(action, seq, offset+1)
+				}
+			}
+			if _, _, ok := next(); !ok {
+				return
+			}
+		default:
+			l.errorf(offset, "expected a key sequence or a list of key sequences")
+		}
+	}
+
+	// Consume the closing brace to catch truncated files.
+	next()
+}
+
+// checkConflicts reports the key sequences of the file that are bound to more
+// than one action.
+func (l *keymapLoader) checkConflicts(bindings []Binding) {
+	owners := make(map[string]string)
+	for _, b := range bindings {
+		if _, listed := l.entries[b.Action]; listed {
+			continue
+		}
+		for _, keys := range b.Keys {
+			if _, ok := owners[keys]; !ok {
+				owners[keys] = b.Action
+			}
+		}
+	}
+	for _, action := range l.order {
+		for _, e := range l.entries[action] {
+			owner, ok := owners[e.keys]
+			switch {
+			case !ok:
+				owners[e.keys] = action
+			case owner != action:
+				l.errorf(e.offset, "%q is already bound to %q", e.keys, owner)
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package tea
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var keymapDefaults = []Binding{
+	{Action: "save", Keys: []string{"ctrl+s"}, Help: "save the file"},
+	{Action: "quit", Keys: []string{"q", "ctrl+c"}, Help: "quit"},
+	{Action: "top", Keys: []string{"g g"}, Help: "go to the top"},
+}
+
+func TestReadKeymap(t *testing.T) {
+	t.Parallel()
+
+	want := []Binding{
+		{Action: "save", Keys: []string{"ctrl+s", "ctrl+x ctrl+s"}, Help: "save the file"},
+		{Action: "quit", Keys: []string{"q", "ctrl+c"}, Help: "quit"},
+		{Action: "top", Keys: []string{}, Help: "go to the top"},
+	}
+
+	for name, keymap := range map[string]string{
+		"json": `{
+  "save": ["ctrl+s", "ctrl+x  ctrl+s"],
+  "top": []
+}`,
+		"ini": `# Emacs style saving.
+save = ctrl+s
+  save=ctrl+x ctrl+s
+
+; Unbind top.
+top =
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ReadKeymap(name, strings.NewReader(keymap), keymapDefaults...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestReadKeymapCanonicalKeys(t *testing.T) {
+	t.Parallel()
+
+	got, err := ReadKeymap("keys.ini", strings.NewReader("top = G\n"), keymapDefaults...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The loaded keys must match the keys decoded from the terminal.
+	km := NewKeymap(got...)
+	out, _, _, _ := km.resolve(KeyPressMsg{Code: 'g', ShiftedCode: 'G', Text: "G", Mod: ModShift})
+	if msg, ok := out.(BindingMsg); !ok || msg.Action != "top" {
+		t.Errorf("expected top binding, got %v", out)
+	}
+}
+
+func TestReadKeymapErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		keymap string
+		errs   []string
+	}{
+		{
+			name:   "ini",
+			keymap: "save = ctrl+s\nsav = ctrl+w\n  quit = ctrl+x nope\ntop\ntop = ctrl+s\n",
+			errs: []string{
+				`ini:2:1: unknown action "sav"`,
+				`ini:3:17: invalid key sequence "ctrl+x nope"`,
+				`ini:4:1: expected "action = keys"`,
+				`ini:5:7: "ctrl+s" is already bound to "save"`,
+			},
+		},
+		{
+			name:   "crlf",
+			keymap: "save = ctrl+s\r\nsav = ctrl+w\r\n  quit = ctrl+x nope\r\ntop\r\ntop = ctrl+s\r\n",
+			errs: []string{
+				`crlf:2:1: unknown action "sav"`,
+				`crlf:3:17: invalid key sequence "ctrl+x nope"`,
+				`crlf:4:1: expected "action = keys"`,
+				`crlf:5:7: "ctrl+s" is already bound to "save"`,
+			},
+		},
+		{
+			name:   "long line",
+			keymap: "# " + strings.Repeat("x", 100000) + "\nsav = ctrl+w\n",
+			errs: []string{
+				`long line:2:1: unknown action "sav"`,
+			},
+		},
+		{
+			name:   "json",
+			keymap: "{\n  \"sav\": \"ctrl+w\",\n  \"quit\": [\"ctrl+x nope\", 1]\n}",
+			errs: []string{
+				`json:2:3: unknown action "sav"`,
+				`json:3:20: invalid key sequence "ctrl+x nope"`,
+				`json:3:27: expected a key sequence`,
+			},
+		},
+		{
+			name:   "conflict with default",
+			keymap: "save = q\n",
+			errs: []string{
+				`conflict with default:1:8: "q" is already bound to "quit"`,
+			},
+		},
+		{
+			name:   "truncated json",
+			keymap: "{\"save\": \"ctrl+s\"",
+			errs:   []string{`truncated json:1:`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ReadKeymap(tc.name, strings.NewReader(tc.keymap), keymapDefaults...)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			var kerr *KeymapError
+			if !errors.As(err, &kerr) {
+				t.Fatalf("expected a *KeymapError, got %T", err)
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(tc.errs) {
+				t.Fatalf("expected %d errors, got %q", len(tc.errs), err)
+			}
+			for i, want := range tc.errs {
+				if !strings.HasPrefix(lines[i], want) {
+					t.Errorf("expected error %q, got %q", want, lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadKeymap(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "keymap.json")
+	if err := os.WriteFile(path, []byte(`{"quit": "esc"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadKeymap(path, keymapDefaults...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := got[1].Keys; !reflect.DeepEqual(keys, []string{"esc"}) {
+		t.Errorf("expected quit to be bound to esc, got %v", keys)
+	}
+}
@@ -0,0 +1,191 @@
+package tea
+
+import (
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeymapResolve(t *testing.T) {
+	t.Parallel()
+
+	g := KeyPressMsg{Code: 'g', Text: "g"}
+	x := KeyPressMsg{Code: 'x', Text: "x"}
+	ctrlX := KeyPressMsg{Code: 'x', Mod: ModCtrl}
+	ctrlS := KeyPressMsg{Code: 's', Mod: ModCtrl}
+	shiftG := KeyPressMsg{Code: 'g', ShiftedCode: 'G', Text: "G", Mod: ModShift}
+
+	newKeymap := func() *Keymap {
+		return NewKeymap(
+			Binding{Action: "top", Keys: []string{"g g"}},
+			Binding{Action: "bottom", Keys: []string{"G"}},
+			Binding{Action: "goto", Keys: []string{"g"}},
+			Binding{Action: "save", Keys: []string{"ctrl+x ctrl+s"}},
+		)
+	}
+
+	action := func(msg Msg) string {
+		if msg, ok := msg.(BindingMsg); ok {
+			return msg.Action
+		}
+		return ""
+	}
+
+	t.Run("chord", func(t *testing.T) {
+		t.Parallel()
+		km := newKeymap()
+		if out, _, wait, _ := km.resolve(g); out != nil || wait != defaultKeymapTimeout {
+			t.Fatalf("expected g to be pending, got %v", out)
+		}
+		if got := km.Active(); len(got) != 2 {
+			t.Errorf("expected 2 active bindings, got %v", got)
+		}
+		out, _, _, _ := km.resolve(g)
+		if action(out) != "top" {
+			t.Fatalf("expected top binding, got %v", out)
+		}
+		if keys := out.(BindingMsg).Keys; !reflect.DeepEqual(keys, []KeyPressMsg{g, g}) {
+			t.Errorf("expected keys g g, got %v", keys)
+		}
+		if len(km.Pending()) != 0 {
+			t.Errorf("expected no pending keys, got %v", km.Pending())
+		}
+	})
+
+	t.Run("shifted", func(t *testing.T) {
+		t.Parallel()
+		if out, _, _, _ := newKeymap().resolve(shiftG); action(out) != "bottom" {
+			t.Fatalf("expected bottom binding, got %v", out)
+		}
+	})
+
+	t.Run("broken chord", func(t *testing.T) {
+		t.Parallel()
+		km := newKeymap()
+		km.resolve(g)
+		out, replay, _, _ := km.resolve(x)
+		if action(out) != "goto" || replay != x {
+			t.Fatalf("expected goto binding and x replay, got %v and %v", out, replay)
+		}
+
+		km.resolve(ctrlX)
+		out, replay, _, _ = km.resolve(x)
+		if out != nil || replay != x {
+			t.Fatalf("expected the chord to be dropped and x replayed, got %v and %v", out, replay)
+		}
+		if out, _, _, _ := km.resolve(x); out != x {
+			t.Fatalf("expected unbound key to pass through, got %v", out)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+		km := newKeymap()
+		_, _, _, gen := km.resolve(g)
+		if out, _, _, _ := km.resolve(keymapTimeoutMsg(gen)); action(out) != "goto" {
+			t.Fatalf("expected goto binding on timeout, got %v", out)
+		}
+
+		_, _, _, gen = km.resolve(ctrlX)
+		km.resolve(ctrlS)
+		if out, _, _, _ := km.resolve(keymapTimeoutMsg(gen)); out != nil {
+			t.Fatalf("expected stale timeout to be ignored, got %v", out)
+		}
+	})
+}
+
+type keymapModel struct {
+	msgs chan Msg
+}
+
+func (m keymapModel) Init() Cmd { return nil }
+
+func (m keymapModel) Update(msg Msg) (Model, Cmd) {
+	switch msg := msg.(type) {
+	case BindingMsg:
+		m.msgs <- msg
+		if msg.Action == "quit" {
+			return m, Quit
+		}
+	case KeyPressMsg:
+		m.msgs <- msg
+	}
+	return m, nil
+}
+
+func (m keymapModel) View() View { return NewView("keymap") }
+
+func TestKeymap(t *testing.T) {
+	t.Parallel()
+
+	pr, pw := io.Pipe()
+	defer pw.Close() //nolint:errcheck
+
+	km := NewKeymap(
+		Binding{Action: "top", Keys: []string{"g g"}},
+		Binding{Action: "quit", Keys: []string{"q"}},
+	)
+	m := keymapModel{msgs: make(chan Msg, 10)}
+	p := NewProgram(m,
+		WithContext(t.Context()),
+		WithInput(pr),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+		WithKeymap(km),
+	)
+
+	go func() {
+		_, _ = io.WriteString(pw, "ggxgaq")
+	}()
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	close(m.msgs)
+
+	var got []string
+	for msg := range m.msgs {
+		switch msg := msg.(type) {
+		case BindingMsg:
+			got = append(got, msg.Action)
+		case KeyPressMsg:
+			got = append(got, msg.String())
+		}
+	}
+	// The "g" before "a" doesn't form a binding on its own, so it's dropped.
+	if want := []string{"top", "x", "a", "quit"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestKeymapTimeout(t *testing.T) {
+	t.Parallel()
+
+	pr, pw := io.Pipe()
+	defer pw.Close() //nolint:errcheck
+
+	km := NewKeymap(
+		Binding{Action: "quit", Keys: []string{"q", "q q"}},
+	)
+	km.SetTimeout(10 * time.Millisecond)
+	m := keymapModel{msgs: make(chan Msg, 10)}
+	p := NewProgram(m,
+		WithContext(t.Context()),
+		WithInput(pr),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+		WithKeymap(km),
+	)
+
+	go func() {
+		_, _ = io.WriteString(pw, "q")
+	}()
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if msg := <-m.msgs; msg.(BindingMsg).Action != "quit" {
+		t.Errorf("expected quit binding, got %v", msg)
+	}
+}
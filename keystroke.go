@@ -0,0 +1,123 @@
+package tea
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// keystrokeMods maps modifier names, as used in keystrokes, to modifiers.
+var keystrokeMods = map[string]KeyMod{
+	"ctrl":       ModCtrl,
+	"alt":        ModAlt,
+	"shift":      ModShift,
+	"meta":       ModMeta,
+	"hyper":      ModHyper,
+	"super":      ModSuper,
+	"capslock":   ModCapsLock,
+	"scrolllock": ModScrollLock,
+	"numlock":    ModNumLock,
+}
+
+// keystrokeNames maps special key names, as returned by [Key.Keystroke], to
+// key codes. Keypad keys share their names with the regular keys, so they're
+// also available with a "kp" prefix, e.g. "kpenter" or "kp5".
+var keystrokeNames = func() map[string]rune {
+	names := map[string]rune{
+		"enter":     KeyEnter,
+		"tab":       KeyTab,
+		"backspace": KeyBackspace,
+		"esc":       KeyEscape,
+		"escape":    KeyEscape,
+		"space":     KeySpace,
+	}
+	for code := KeyUp; code <= KeyIsoLevel5Shift; code++ {
+		name := Key{Code: code}.Keystroke()
+		if code >= KeyKpEnter && code <= KeyKpBegin {
+			names["kp"+name] = code
+		}
+		if _, ok := names[name]; ok || utf8.RuneCountInString(name) == 1 {
+			// Single characters, like the keypad digits, are parsed as
+			// regular keys.
+			continue
+		}
+		names[name] = code
+	}
+	return names
+}()
+
+// ParseKeystroke parses a keystroke, as returned by [Key.Keystroke] or
+// [Key.String], into a [Key]. It's the inverse of [Key.Keystroke]:
+//
+//	k, _ := tea.ParseKeystroke("ctrl+shift+a")
+//	k.Keystroke() // "ctrl+shift+a"
+//
+// A keystroke is made of zero or more modifiers followed by a key, separated
+// by "+". Modifiers can come in any order. The key is either the name of a
+// special key, like "enter", "pgup", or "f1", or a single character, like
+// "a", "?", or "+". Upper case letters are parsed as their shifted lower case
+// counterparts, so "A" is the same as "shift+a". A single grapheme made of
+// several runes, like an emoji, is parsed as a [KeyExtended] key.
+//
+// Printable keys without modifiers other than shift get their [Key.Text] set,
+// just like the keys Bubble Tea decodes from the terminal.
+func ParseKeystroke(s string) (Key, error) {
+	var k Key
+	if s == "" {
+		return k, fmt.Errorf("bubbletea: empty keystroke")
+	}
+
+	// The last part is the key. A trailing "+" is the plus key itself.
+	name := s
+	if i := strings.LastIndex(s[:len(s)-1], "+"); i >= 0 {
+		name = s[i+1:]
+		for _, mod := range strings.Split(s[:i], "+") {
+			m, ok := keystrokeMods[mod]
+			if !ok {
+				return Key{}, fmt.Errorf("bubbletea: invalid keystroke %q: unknown modifier %q", s, mod)
+			}
+			if k.Mod.Contains(m) {
+				return Key{}, fmt.Errorf("bubbletea: invalid keystroke %q: duplicate modifier %q", s, mod)
+			}
+			k.Mod |= m
+		}
+	}
+
+	if code, ok := keystrokeNames[name]; ok {
+		k.Code = code
+		if code == KeySpace && k.Mod&^ModShift == 0 {
+			k.Text = " "
+		}
+		return k, nil
+	}
+
+	switch r, size := utf8.DecodeRuneInString(name); {
+	case size == len(name) && r != utf8.RuneError:
+		k.Code = r
+		if unicode.IsUpper(r) {
+			// Upper case letters are shifted lower case letters.
+			k.Code = unicode.ToLower(r)
+			k.ShiftedCode = r
+			k.Mod |= ModShift
+		} else if k.Mod.Contains(ModShift) && unicode.ToUpper(r) != r {
+			k.ShiftedCode = unicode.ToUpper(r)
+		}
+		if unicode.IsPrint(r) && k.Mod&^ModShift == 0 {
+			k.Text = string(r)
+			if k.ShiftedCode != 0 {
+				k.Text = string(k.ShiftedCode)
+			}
+		}
+	default:
+		if cluster, _ := ansi.FirstGraphemeCluster(name, ansi.GraphemeWidth); cluster != name {
+			return Key{}, fmt.Errorf("bubbletea: invalid keystroke %q: unknown key %q", s, name)
+		}
+		k.Code = KeyExtended
+		k.Text = name
+	}
+
+	return k, nil
+}
@@ -0,0 +1,76 @@
+package tea
+
+import (
+	"testing"
+)
+
+func TestParseKeystroke(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		keystroke string
+		want      Key
+	}{
+		{"a", Key{Code: 'a', Text: "a"}},
+		{"A", Key{Code: 'a', ShiftedCode: 'A', Text: "A", Mod: ModShift}},
+		{"shift+a", Key{Code: 'a', ShiftedCode: 'A', Text: "A", Mod: ModShift}},
+		{"ctrl+a", Key{Code: 'a', Mod: ModCtrl}},
+		{"shift+ctrl+alt+a", Key{Code: 'a', ShiftedCode: 'A', Mod: ModCtrl | ModAlt | ModShift}},
+		{"?", Key{Code: '?', Text: "?"}},
+		{"+", Key{Code: '+', Text: "+"}},
+		{"ctrl++", Key{Code: '+', Mod: ModCtrl}},
+		{"space", Key{Code: KeySpace, Text: " "}},
+		{"ctrl+space", Key{Code: KeySpace, Mod: ModCtrl}},
+		{"enter", Key{Code: KeyEnter}},
+		{"escape", Key{Code: KeyEscape}},
+		{"alt+pgup", Key{Code: KeyPgUp, Mod: ModAlt}},
+		{"f12", Key{Code: KeyF12}},
+		{"kp5", Key{Code: KeyKp5}},
+		{"5", Key{Code: '5', Text: "5"}},
+		{"super+mediaplay", Key{Code: KeyMediaPlay, Mod: ModSuper}},
+		{"👍🏽", Key{Code: KeyExtended, Text: "👍🏽"}},
+	} {
+		got, err := ParseKeystroke(tc.keystroke)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.keystroke, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%q: expected %+v, got %+v", tc.keystroke, tc.want, got)
+		}
+	}
+
+	for _, keystroke := range []string{
+		"",
+		"ctrl+",
+		"ctrl+ctrl+a",
+		"foo+a",
+		"notakey",
+	} {
+		if _, err := ParseKeystroke(keystroke); err == nil {
+			t.Errorf("%q: expected an error", keystroke)
+		}
+	}
+}
+
+func TestParseKeystrokeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	codes := []rune{KeyBackspace, KeyTab, KeyEnter, KeyEscape, KeySpace, 'a', '1', '/'}
+	for code := KeyUp; code <= KeyIsoLevel5Shift; code++ {
+		codes = append(codes, code)
+	}
+	for _, code := range codes {
+		for _, mod := range []KeyMod{0, ModCtrl, ModAlt | ModShift, ModCtrl | ModMeta | ModHyper | ModSuper} {
+			keystroke := Key{Code: code, Mod: mod}.Keystroke()
+			k, err := ParseKeystroke(keystroke)
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", keystroke, err)
+				continue
+			}
+			if got := k.Keystroke(); got != keystroke {
+				t.Errorf("expected %q to round trip, got %q", keystroke, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package tea
+
+import (
+	"context"
+	"time"
+)
+
+// modalMsg is an internal message that starts a modal model.
+type modalMsg struct {
+	model Model
+}
+
+// closeModalMsg is an internal message that closes a modal once its model
+// returns [Quit].
+type closeModalMsg struct {
+	id uint64
+}
+
+// modal is a running modal model.
+type modal struct {
+	id    uint64
+	model Model
+}
+
+// ModalResultMsg is sent to the model that ran [RunModal] once the modal
+// model quits. It holds the final state of the modal model.
+type ModalResultMsg struct {
+	Model Model
+}
+
+// RunModal is a command that runs child as a modal model, like a picker or a
+// confirmation dialog, within the running program. The child takes over the
+// program: its Init command is run, it receives every message and its view is
+// rendered in place of the parent's. The subscriptions of the child replace
+// the parent's while it runs.
+//
+// Messages that describe the terminal, like [WindowSizeMsg], [ColorProfileMsg],
+// [BackgroundColorMsg], or [FocusMsg], go to the parent as well, so that it's
+// up to date once the child is closed. Every other message, including the
+// results of commands the parent started before the modal, only goes to the
+// child, and the parent never receives it. Wait for the results of such
+// commands before running a modal.
+//
+// When the child returns [Quit], directly or from any command it runs, like a
+// [Batch], a [Sequence], or a [Stream], it's closed and the parent receives a
+// [ModalResultMsg] with the final child model. The program keeps running.
+// Quitting the program by other means, like [Program.Quit] or a signal, still
+// ends it. Modals can be nested.
+//
+//	func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+//	    switch msg := msg.(type) {
+//	    case tea.KeyPressMsg:
+//	        if msg.String() == "d" {
+//	            return m, tea.RunModal(newConfirm("Delete the file?"))
+//	        }
+//	    case tea.ModalResultMsg:
+//	        if c, ok := msg.Model.(confirm); ok && c.yes {
+//	            return m, deleteFile(m.path)
+//	        }
+//	    }
+//	    return m, nil
+//	}
+func RunModal(child Model) Cmd {
+	if child == nil {
+		return nil
+	}
+	return func() Msg {
+		return modalMsg{model: child}
+	}
+}
+
+// activeModel returns the model that receives messages and renders the view:
+// the innermost modal, if any, or the program's model.
+func (p *Program) activeModel(model Model) Model {
+	if n := len(p.modals); n > 0 {
+		return p.modals[n-1].model
+	}
+	return model
+}
+
+// updateModel updates the active model with msg. Messages that describe the
+// terminal also update the models below the active one.
+func (p *Program) updateModel(model Model, msg Msg) (Model, Cmd) {
+	n := len(p.modals)
+	if n == 0 {
+		return model.Update(msg)
+	}
+
+	var cmds []Cmd
+	if isTerminalMsg(msg) {
+		var cmd Cmd
+		model, cmd = model.Update(msg)
+		cmds = append(cmds, cmd)
+		for i := range p.modals[:n-1] {
+			cmds = append(cmds, p.updateModal(i, msg))
+		}
+	}
+	cmds = append(cmds, p.updateModal(n-1, msg))
+	return model, Batch(cmds...)
+}
+
+// updateModal updates the i-th modal with msg.
+func (p *Program) updateModal(i int, msg Msg) Cmd {
+	var cmd Cmd
+	m := &p.modals[i]
+	m.model, cmd = m.model.Update(msg)
+	return modalCmd(m.id, cmd)
+}
+
+// isTerminalMsg reports whether msg describes the terminal, which every model
+// needs to know about, including the ones below a modal.
+func isTerminalMsg(msg Msg) bool {
+	switch msg.(type) {
+	case WindowSizeMsg, CellSizeMsg, ColorProfileMsg, ColorSchemeMsg,
+		BackgroundColorMsg, ForegroundColorMsg, CursorColorMsg,
+		KeyboardEnhancementsMsg, TerminalCapabilitiesMsg, FocusMsg, BlurMsg:
+		return true
+	}
+	return false
+}
+
+// openModal starts child as the innermost modal and returns its Init
+// command.
+func (p *Program) openModal(child Model) Cmd {
+	p.modalSeq++
+	p.modals = append(p.modals, modal{id: p.modalSeq, model: child})
+	return modalCmd(p.modalSeq, child.Init())
+}
+
+// closeModal closes the modal with the given id, along with the modals it
+// opened, and returns the message to deliver to the model below it. It
+// returns nil if the modal is already closed.
+func (p *Program) closeModal(id uint64) Msg {
+	for i := len(p.modals) - 1; i >= 0; i-- {
+		if p.modals[i].id != id {
+			continue
+		}
+		child := p.modals[i].model
+		clear(p.modals[i:])
+		p.modals = p.modals[:i]
+		return ModalResultMsg{Model: child}
+	}
+	return nil
+}
+
+// modalCmd turns the [Quit] returned by cmd, or by the commands it runs, into
+// a message that closes the modal with the given id.
+func modalCmd(id uint64, cmd Cmd) Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() Msg {
+		return modalResult(id, cmd())
+	}
+}
+
+// modalResult turns msg into a message that closes the modal with the given
+// id if it's a [QuitMsg]. Internal messages that run commands have their
+// commands wrapped with [modalCmd], so that the modal can quit from a
+// [Batch], a [Sequence], a [Stream], or any other command combinator.
+func modalResult(id uint64, msg Msg) Msg {
+	switch msg := msg.(type) {
+	case QuitMsg:
+		return closeModalMsg{id: id}
+	case BatchMsg:
+		return BatchMsg(modalCmds(id, msg))
+	case sequenceMsg:
+		return sequenceMsg(modalCmds(id, msg))
+	case sequenceUntilMsg:
+		msg.cmds = modalCmds(id, msg.cmds)
+		return msg
+	case timeoutMsg:
+		msg.cmd = modalCmd(id, msg.cmd)
+		msg.onTimeout = modalResult(id, msg.onTimeout)
+		return msg
+	case retryMsg:
+		msg.cmd = modalCmd(id, msg.cmd)
+		return msg
+	case debounceMsg:
+		msg.cmd = modalCmd(id, msg.cmd)
+		return msg
+	case throttleMsg:
+		msg.cmd = modalCmd(id, msg.cmd)
+		return msg
+	case groupMsg:
+		msg.cmd = modalCmd(id, msg.cmd)
+		return msg
+	case timerMsg:
+		fn := msg.fn
+		msg.fn = func(t time.Time) Msg {
+			return modalResult(id, fn(t))
+		}
+		return msg
+	case streamMsg:
+		fn := msg.fn
+		msg.fn = func(ctx context.Context, emit func(Msg)) error {
+			return fn(ctx, func(msg Msg) {
+				emit(modalResult(id, msg))
+			})
+		}
+		return msg
+	case execMsg:
+		if fn := msg.fn; fn != nil {
+			msg.fn = func(err error) Msg {
+				return modalResult(id, fn(err))
+			}
+		}
+		return msg
+	default:
+		return msg
+	}
+}
+
+// modalCmds applies [modalCmd] to each command.
+func modalCmds(id uint64, cmds []Cmd) []Cmd {
+	wrapped := make([]Cmd, len(cmds))
+	for i, cmd := range cmds {
+		wrapped[i] = modalCmd(id, cmd)
+	}
+	return wrapped
+}
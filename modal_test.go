@@ -0,0 +1,276 @@
+package tea
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type modalChild struct {
+	name   string
+	views  *atomic.Int32
+	nested Model
+	wrap   func(Cmd) Cmd
+	got    []Msg
+}
+
+func (m modalChild) Init() Cmd {
+	if m.nested != nil {
+		return RunModal(m.nested)
+	}
+	return func() Msg { return m.name }
+}
+
+func (m modalChild) quit() Cmd {
+	if m.wrap != nil {
+		return m.wrap(Quit)
+	}
+	return Quit
+}
+
+func (m modalChild) Update(msg Msg) (Model, Cmd) {
+	switch msg := msg.(type) {
+	case string:
+		m.got = append(m.got, msg)
+		return m, m.quit()
+	case ModalResultMsg:
+		m.got = append(m.got, msg.Model.(modalChild).name)
+		return m, m.quit()
+	}
+	return m, nil
+}
+
+func (m modalChild) View() View {
+	m.views.Add(1)
+	return NewView(m.name)
+}
+
+type modalParent struct {
+	child  Model
+	result Model
+	got    []Msg
+	width  int
+}
+
+func (m modalParent) Init() Cmd {
+	return RunModal(m.child)
+}
+
+func (m modalParent) Update(msg Msg) (Model, Cmd) {
+	switch msg := msg.(type) {
+	case string:
+		m.got = append(m.got, msg)
+	case WindowSizeMsg:
+		m.width = msg.Width
+	case ModalResultMsg:
+		m.result = msg.Model
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m modalParent) View() View {
+	return NewView("parent")
+}
+
+func runModalParent(t *testing.T, child Model) (modalParent, *Program) {
+	t.Helper()
+
+	p := NewProgram(modalParent{child: child},
+		WithContext(t.Context()),
+		WithInput(nil),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+	)
+	m, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.(modalParent), p
+}
+
+func TestRunModal(t *testing.T) {
+	t.Parallel()
+
+	var views atomic.Int32
+	m, p := runModalParent(t, modalChild{name: "picker", views: &views})
+
+	child, ok := m.result.(modalChild)
+	if !ok {
+		t.Fatalf("expected the parent to receive the child, got %#v", m.result)
+	}
+	if len(child.got) != 1 || child.got[0] != "picker" {
+		t.Errorf("expected the child to receive its init message, got %v", child.got)
+	}
+	if len(m.got) != 0 {
+		t.Errorf("expected the parent to receive no child messages, got %v", m.got)
+	}
+	if views.Load() == 0 {
+		t.Error("expected the child view to be rendered")
+	}
+	if len(p.modals) != 0 {
+		t.Errorf("expected no modal to be left, got %d", len(p.modals))
+	}
+}
+
+func TestRunModalNested(t *testing.T) {
+	t.Parallel()
+
+	var views atomic.Int32
+	m, _ := runModalParent(t, modalChild{
+		name:   "outer",
+		views:  &views,
+		nested: modalChild{name: "inner", views: &views},
+	})
+
+	outer, ok := m.result.(modalChild)
+	if !ok || outer.name != "outer" {
+		t.Fatalf("expected the parent to receive the outer child, got %#v", m.result)
+	}
+	if len(outer.got) != 1 || outer.got[0] != "inner" {
+		t.Errorf("expected the outer child to receive the inner one, got %v", outer.got)
+	}
+}
+
+func TestRunModalQuitFromCommand(t *testing.T) {
+	t.Parallel()
+
+	noop := func() Msg { return nil }
+	for name, wrap := range map[string]func(Cmd) Cmd{
+		"batch":    func(cmd Cmd) Cmd { return Batch(noop, cmd) },
+		"sequence": func(cmd Cmd) Cmd { return Sequence(noop, cmd) },
+		"sequence until": func(cmd Cmd) Cmd {
+			return SequenceUntil(IsError, noop, cmd)
+		},
+		"timeout": func(cmd Cmd) Cmd {
+			return Timeout(func() Msg { time.Sleep(time.Second); return nil }, time.Millisecond, QuitMsg{})
+		},
+		"retry":    func(cmd Cmd) Cmd { return Retry(cmd, RetryPolicy{}) },
+		"group":    func(cmd Cmd) Cmd { return InGroup("modal", cmd) },
+		"debounce": func(cmd Cmd) Cmd { return Debounce("modal", time.Millisecond, cmd) },
+		"throttle": func(cmd Cmd) Cmd { return Throttle("modal", time.Second, cmd) },
+		"tick": func(Cmd) Cmd {
+			return Tick(time.Millisecond, func(time.Time) Msg { return QuitMsg{} })
+		},
+		"stream": func(Cmd) Cmd {
+			return Stream("modal", func(_ context.Context, emit func(Msg)) error {
+				emit(QuitMsg{})
+				return nil
+			})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var views atomic.Int32
+			m, _ := runModalParent(t, modalChild{name: "picker", views: &views, wrap: wrap})
+			if child, ok := m.result.(modalChild); !ok || child.name != "picker" {
+				t.Fatalf("expected the parent to receive the child, got %#v", m.result)
+			}
+		})
+	}
+}
+
+// openModalChild is a modal that reports when it's open and never quits.
+type openModalChild struct {
+	open chan struct{}
+}
+
+func (m openModalChild) Init() Cmd {
+	return func() Msg {
+		close(m.open)
+		return nil
+	}
+}
+
+func (m openModalChild) Update(Msg) (Model, Cmd) { return m, nil }
+
+func (m openModalChild) View() View { return NewView("open") }
+
+func TestRunModalProgramQuit(t *testing.T) {
+	t.Parallel()
+
+	child := openModalChild{open: make(chan struct{})}
+	p := NewProgram(modalParent{child: child},
+		WithContext(t.Context()),
+		WithInput(nil),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+	)
+	go func() {
+		<-child.open
+		p.Quit()
+	}()
+
+	m, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := m.(modalParent).result; result != nil {
+		t.Errorf("expected the modal to stay open, got %#v", result)
+	}
+	if len(p.modals) != 1 {
+		t.Errorf("expected the modal to be left open, got %d", len(p.modals))
+	}
+}
+
+// resizeModalChild is a modal that quits once it's resized.
+type resizeModalChild struct {
+	open  chan struct{}
+	width int
+}
+
+func (m resizeModalChild) Init() Cmd {
+	return func() Msg {
+		close(m.open)
+		return nil
+	}
+}
+
+func (m resizeModalChild) Update(msg Msg) (Model, Cmd) {
+	if msg, ok := msg.(WindowSizeMsg); ok && msg.Width == 42 {
+		m.width = msg.Width
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m resizeModalChild) View() View { return NewView("resize") }
+
+func TestRunModalTerminalMsgs(t *testing.T) {
+	t.Parallel()
+
+	child := resizeModalChild{open: make(chan struct{})}
+	p := NewProgram(modalParent{child: child},
+		WithContext(t.Context()),
+		WithInput(nil),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+	)
+	go func() {
+		<-child.open
+		p.Send(WindowSizeMsg{Width: 42, Height: 10})
+	}()
+
+	m, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := m.(modalParent)
+	if result, ok := parent.result.(resizeModalChild); !ok || result.width != 42 {
+		t.Errorf("expected the child to be resized, got %#v", parent.result)
+	}
+	if parent.width != 42 {
+		t.Errorf("expected the parent to be resized too, got a width of %d", parent.width)
+	}
+}
+
+func TestRunModalNil(t *testing.T) {
+	t.Parallel()
+
+	if RunModal(nil) != nil {
+		t.Error("expected a nil command for a nil model")
+	}
+}
@@ -2,10 +2,16 @@ package tea
 
 import (
 	"fmt"
+	"time"
 
 	uv "github.com/charmbracelet/ultraviolet"
 )
 
+// mousePixelsTimeout is the maximum amount of time mouse messages are
+// dropped while waiting for the terminal to report a change of the pixel
+// mouse mode.
+const mousePixelsTimeout = 200 * time.Millisecond
+
 // MouseButton represents the button that was pressed during a mouse message.
 type MouseButton = uv.MouseButton
 
@@ -41,6 +47,29 @@ const (
 	MouseButton11   = uv.MouseButton11
 )
 
+// MousePointer is the shape of the mouse pointer. The shape names follow the
+// CSS cursor names, and any other name the terminal understands can be used.
+type MousePointer string
+
+// Mouse pointer shapes.
+const (
+	MousePointerDefault    MousePointer = "default"
+	MousePointerPointer    MousePointer = "pointer"
+	MousePointerText       MousePointer = "text"
+	MousePointerCrosshair  MousePointer = "crosshair"
+	MousePointerGrab       MousePointer = "grab"
+	MousePointerGrabbing   MousePointer = "grabbing"
+	MousePointerMove       MousePointer = "move"
+	MousePointerWait       MousePointer = "wait"
+	MousePointerProgress   MousePointer = "progress"
+	MousePointerHelp       MousePointer = "help"
+	MousePointerNotAllowed MousePointer = "not-allowed"
+	MousePointerColResize  MousePointer = "col-resize"
+	MousePointerRowResize  MousePointer = "row-resize"
+	MousePointerEWResize   MousePointer = "ew-resize"
+	MousePointerNSResize   MousePointer = "ns-resize"
+)
+
 // MouseMsg represents a mouse message. This is a generic mouse message that
 // can represent any kind of mouse event.
 type MouseMsg interface {
@@ -54,7 +83,10 @@ type MouseMsg interface {
 // messages.
 //
 // The X and Y coordinates are zero-based, with (0,0) being the upper left
-// corner of the terminal.
+// corner of the program's frame. In the alt screen, that's the upper left
+// corner of the terminal. Inline, Bubble Tea asks the terminal where the
+// frame starts; until it answers, the coordinates are relative to the
+// terminal. Mouse events above the frame have a negative Y.
 //
 //	// Catch all mouse events
 //	switch msg := msg.(type) {
@@ -72,11 +104,77 @@ type Mouse struct {
 	X, Y   int
 	Button MouseButton
 	Mod    KeyMod
+
+	// PixelX and PixelY are the pointer position in pixels, relative to the
+	// upper left corner of the terminal. They're only set when
+	// [View.MousePixels] is enabled and supported by the terminal.
+	PixelX, PixelY int
+
+	// Delta is the number of wheel steps of a [MouseWheelMsg]. It's 1 unless
+	// several wheel events were merged by [WithInputCoalescing].
+	Delta int
 }
 
 // String returns a string representation of the mouse message.
 func (m Mouse) String() (s string) {
-	return uv.Mouse(m).String()
+	return uv.Mouse{X: m.X, Y: m.Y, Button: m.Button, Mod: m.Mod}.String()
+}
+
+// mouse converts a mouse event into a [Mouse]. When pixel precision mouse
+// reporting is active, the event holds pixel coordinates and the cell
+// coordinates are computed from the cell size.
+func (p *Program) mouse(m uv.Mouse) Mouse {
+	if !p.mousePixels {
+		return p.frameMouse(Mouse{X: m.X, Y: m.Y, Button: m.Button, Mod: m.Mod})
+	}
+	mouse := Mouse{Button: m.Button, Mod: m.Mod, PixelX: m.X, PixelY: m.Y}
+	if w, h := p.mouseCellSize(); w > 0 && h > 0 {
+		mouse.X, mouse.Y = m.X/w, m.Y/h
+	}
+	return p.frameMouse(mouse)
+}
+
+// mouseCellSize returns the size of a cell in pixels, as reported by the
+// terminal or derived from the window size. It returns zeros if it's unknown.
+func (p *Program) mouseCellSize() (width, height int) {
+	if p.cellSize.Width > 0 && p.cellSize.Height > 0 {
+		return p.cellSize.Width, p.cellSize.Height
+	}
+	if p.windowCells.Width > 0 && p.windowCells.Height > 0 {
+		return p.windowPixels.Width / p.windowCells.Width, p.windowPixels.Height / p.windowCells.Height
+	}
+	return 0, 0
+}
+
+// dropMouse reports whether msg is a mouse message that arrived after the
+// renderer changed the pixel mouse mode, but before the terminal reported the
+// change. Its coordinates could be in cells or in pixels. In case the
+// terminal never answers, mouse messages are only dropped for
+// mousePixelsTimeout.
+func (p *Program) dropMouse(msg Msg) bool {
+	if p.renderer == nil || !p.renderer.mousePixelsPending() {
+		p.mousePixelsWait = time.Time{}
+		return false
+	}
+	if _, ok := msg.(MouseMsg); !ok {
+		return false
+	}
+	now := p.clock.Now()
+	if p.mousePixelsWait.IsZero() {
+		p.mousePixelsWait = now
+	}
+	return now.Sub(p.mousePixelsWait) < mousePixelsTimeout
+}
+
+// frameMouse makes the mouse coordinates relative to the program's frame.
+func (p *Program) frameMouse(m Mouse) Mouse {
+	if p.renderer == nil {
+		return m
+	}
+	if y, ok := p.renderer.frameOrigin(); ok {
+		m.Y -= y
+	}
+	return m
 }
 
 // MouseClickMsg represents a mouse button click message.
@@ -0,0 +1,119 @@
+package tea
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	uv "github.com/charmbracelet/ultraviolet"
+)
+
+func TestMousePixels(t *testing.T) {
+	t.Parallel()
+
+	p := NewProgram(nil)
+	event := uv.MouseClickEvent{X: 25, Y: 37, Button: MouseLeft}
+
+	if got, want := p.translateInputEvent(event), (MouseClickMsg{X: 25, Y: 37, Button: MouseLeft}); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// Once the terminal confirms the mode and reports the cell size, mouse
+	// coordinates are read in pixels.
+	p.mousePixels = true
+	p.cellSize = p.translateInputEvent(uv.CellSizeEvent{Width: 10, Height: 20}).(CellSizeMsg)
+
+	want := MouseClickMsg{X: 2, Y: 1, Button: MouseLeft, PixelX: 25, PixelY: 37}
+	if got := p.translateInputEvent(event); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestMousePixelsWithoutCellSize(t *testing.T) {
+	t.Parallel()
+
+	p := NewProgram(nil)
+	p.mousePixels = true
+	event := uv.MouseClickEvent{X: 25, Y: 37, Button: MouseLeft}
+
+	// Without any size, only the pixel coordinates are known.
+	want := MouseClickMsg{Button: MouseLeft, PixelX: 25, PixelY: 37}
+	if got := p.translateInputEvent(event); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// The terminal doesn't report the cell size, but it reports the window
+	// size in pixels.
+	p.windowCells = WindowSizeMsg{Width: 80, Height: 24}
+	p.windowPixels = uv.PixelSizeEvent{Width: 800, Height: 480}
+	want = MouseClickMsg{X: 2, Y: 1, Button: MouseLeft, PixelX: 25, PixelY: 37}
+	if got := p.translateInputEvent(event); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestMouseFrameRelative(t *testing.T) {
+	t.Parallel()
+
+	r := newCursedRenderer(io.Discard, []string{"TERM=xterm-256color"}, 80, 24)
+	r.start()
+	view := NewView("hello")
+	view.MouseMode = MouseModeCellMotion
+	r.render(view)
+	if err := r.flush(false); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProgram(nil)
+	p.renderer = r
+	event := uv.MouseClickEvent{X: 3, Y: 12, Button: MouseLeft}
+
+	// Until the terminal reports where the frame starts, coordinates are
+	// relative to the terminal.
+	if got, want := p.translateInputEvent(event), (MouseClickMsg{X: 3, Y: 12, Button: MouseLeft}); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	r.cursorPosition(0, 10, false)
+	if got, want := p.translateInputEvent(event), (MouseClickMsg{X: 3, Y: 2, Button: MouseLeft}); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDropMouseWhilePixelsPending(t *testing.T) {
+	t.Parallel()
+
+	r := newCursedRenderer(io.Discard, []string{"TERM=xterm-256color"}, 80, 24)
+	r.start()
+	view := NewView("hello")
+	view.MouseMode = MouseModeCellMotion
+	view.MousePixels = true
+	r.render(view)
+	if err := r.flush(false); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := NewFakeClock(time.Now())
+	p := NewProgram(nil, WithClock(clock))
+	p.renderer = r
+	click := MouseClickMsg{X: 3, Y: 12, Button: MouseLeft}
+
+	// Until the terminal reports the mode, mouse coordinates are ambiguous.
+	if !p.dropMouse(click) {
+		t.Error("expected mouse messages to be dropped while the mode is pending")
+	}
+	if p.dropMouse(KeyPressMsg{Code: 'a'}) {
+		t.Error("expected other messages to be kept")
+	}
+
+	// We give up on terminals that don't answer.
+	clock.Advance(mousePixelsTimeout)
+	if p.dropMouse(click) {
+		t.Error("expected mouse messages to be kept after the timeout")
+	}
+
+	r.mousePixelsReported()
+	if p.dropMouse(click) || !p.mousePixelsWait.IsZero() {
+		t.Error("expected mouse messages to be kept once the mode is reported")
+	}
+}
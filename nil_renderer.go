@@ -51,3 +51,26 @@ func (n nilRenderer) setWidthMethod(ansi.Method) {}
 func (n nilRenderer) onMouse(MouseMsg) Cmd {
 	return nil
 }
+
+// requestCursorPosition implements the Renderer interface.
+func (n nilRenderer) requestCursorPosition() bool {
+	return false
+}
+
+// cursorPosition implements the Renderer interface.
+func (n nilRenderer) cursorPosition(int, int, bool) bool {
+	return false
+}
+
+// frameOrigin implements the Renderer interface.
+func (n nilRenderer) frameOrigin() (int, bool) {
+	return 0, false
+}
+
+// mousePixelsReported implements the Renderer interface.
+func (n nilRenderer) mousePixelsReported() {}
+
+// mousePixelsPending implements the Renderer interface.
+func (n nilRenderer) mousePixelsPending() bool {
+	return false
+}
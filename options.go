@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"sync/atomic"
+	"time"
 
 	"github.com/charmbracelet/colorprofile"
 )
@@ -166,3 +167,174 @@ func WithWindowSize(width, height int) ProgramOption {
 		p.height = height
 	}
 }
+
+// WithCapabilityProbe queries the terminal for its capabilities when the
+// program starts. Bubble Tea asks for the terminal version (XTVERSION), device
+// attributes (DA1 and DA2), true color support (XTGETTCAP), synchronized
+// output, unicode core, and color scheme report modes (DECRQM), and Kitty
+// keyboard support. Once the terminal has answered, or the timeout has
+// elapsed, the program receives a [TerminalCapabilitiesMsg]. A timeout less
+// than 1 uses the default of 500ms.
+//
+// The capabilities can also be read at any time with [Program.Capabilities].
+func WithCapabilityProbe(timeout time.Duration) ProgramOption {
+	return func(p *Program) {
+		p.probeCapabilities = true
+		if timeout < 1 {
+			timeout = defaultCapabilityProbeTimeout
+		}
+		p.probeTimeout = timeout
+	}
+}
+
+// WithKeymap installs a [Keymap] in the program. Key presses that match one
+// of its bindings are sent to the program as a [BindingMsg] instead. Keep a
+// reference to the keymap to change its bindings at runtime or to render
+// help views from [Keymap.Active].
+func WithKeymap(km *Keymap) ProgramOption {
+	return func(p *Program) {
+		p.keymap = km
+	}
+}
+
+// WithMouseGestures enables mouse gestures. On top of the raw mouse
+// messages, the program receives a [MouseDoubleClickMsg] and a
+// [MouseTripleClickMsg] on repeated clicks, a [MouseDragStartMsg],
+// [MouseDragMsg], and [MouseDragEndMsg] when the pointer moves with a button
+// held down, and a [MouseHoverEnterMsg] and [MouseHoverLeaveMsg] when the
+// pointer rests on a cell. Use the [MouseGestures] fields to tune the
+// thresholds, or pass the zero value to use the defaults.
+//
+// Mouse gestures need mouse reporting to be enabled with [View.MouseMode].
+func WithMouseGestures(g MouseGestures) ProgramOption {
+	return func(p *Program) {
+		p.gestures = newGestureTracker(g)
+	}
+}
+
+// WithInputCoalescing merges consecutive input events that pile up while the
+// program is busy, according to the given policies. This keeps the program
+// responsive when it can't keep up with a flood of mouse motion, mouse wheel,
+// or key repeat events, at the cost of skipping the intermediate events. For
+// example, to only handle the latest mouse position and to add up the wheel
+// steps:
+//
+//	p := tea.NewProgram(model, tea.WithInputCoalescing(tea.InputCoalescing{
+//		MouseMotion: tea.CoalesceLatest,
+//		MouseWheel:  tea.CoalesceSum,
+//	}))
+//
+// Merged wheel events report the number of steps in [Mouse.Delta].
+func WithInputCoalescing(c InputCoalescing) ProgramOption {
+	return func(p *Program) {
+		p.coalescing = &c
+	}
+}
+
+// WithPasteStreaming delivers bracketed pastes in chunks. Instead of a single
+// [PasteMsg], the program receives a [PasteStartMsg], a series of
+// [PasteChunkMsg], and a [PasteEndMsg]. This lets the program handle large
+// pastes piece by piece, without holding the whole text in memory. Use
+// [PasteStreaming.MaxSize] to limit the size of the pasted text, in which case
+// [PasteEndMsg.Truncated] reports whether the rest of the text was dropped.
+//
+// Since the pasted text is never read as a whole, files dropped onto the
+// terminal aren't recognized by [WithFileDrops] when paste streaming is
+// enabled, except on Windows.
+func WithPasteStreaming(s PasteStreaming) ProgramOption {
+	return func(p *Program) {
+		p.pasteStreaming = &s
+	}
+}
+
+// WithFileDrops recognizes files dragged and dropped onto the terminal. When
+// a paste is only made of the paths or file:// URIs of existing files, the
+// program receives a [FileDropMsg] in place of the [PasteMsg].
+//
+// Dropping files requires bracketed paste, which is enabled unless
+// [View.DisableBracketedPasteMode] is set.
+func WithFileDrops() ProgramOption {
+	return func(p *Program) {
+		p.fileDrops = true
+	}
+}
+
+// WithMaxConcurrentCommands limits the number of commands that run at once
+// to n. Commands in excess wait, in order, for the running ones to return. By
+// default, there's no limit. Use [Program.CommandStats] to observe the
+// running and waiting commands.
+//
+// Only the command functions count towards the limit, while they run. A
+// command that blocks, waiting on a channel for example, holds its slot until
+// it returns, so a limit of 1 lets it block every other command. Timers
+// started with [Tick] and [Every], and streams started with [Stream], don't
+// count.
+func WithMaxConcurrentCommands(n int) ProgramOption {
+	return func(p *Program) {
+		p.commands.max = max(n, 0)
+	}
+}
+
+// WithCommandGroupLimit limits the number of commands of the given group,
+// created with [InGroup], that run at once to n. Commands in excess wait, in
+// order, for the running ones of the group to return. Grouped commands also
+// count towards the limit set with [WithMaxConcurrentCommands]. The empty
+// group stands for all commands.
+func WithCommandGroupLimit(group string, n int) ProgramOption {
+	return func(p *Program) {
+		p.commands.mu.Lock()
+		defer p.commands.mu.Unlock()
+		if g := p.commands.group(group); g != nil {
+			g.max = max(n, 0)
+		} else {
+			p.commands.max = max(n, 0)
+		}
+	}
+}
+
+// WithClock sets the clock of the program. Everything that depends on time
+// uses it: [Tick], [Every], [Timeout], [Retry], [Debounce], [Throttle],
+// [IntervalSub], and the keymap, mouse gesture, and terminal query timeouts.
+// It's meant for tests, where a [FakeClock] makes time-dependent models
+// deterministic. The renderer keeps drawing frames on the system clock.
+func WithClock(c Clock) ProgramOption {
+	return func(p *Program) {
+		if c != nil {
+			p.clock = c
+		}
+	}
+}
+
+// WithStartupQueries sends the given terminal queries when the program starts
+// and waits for the terminal to answer them before initializing the model and
+// rendering the first frame. The replies are delivered to Update right after
+// Init, before the first frame is drawn. This avoids rendering a frame with
+// the wrong colors while waiting for a [BackgroundColorMsg], for example.
+//
+// Queries are commands like [RequestBackgroundColor],
+// [RequestForegroundColor], [RequestCursorColor], [RequestTerminalVersion],
+// [RequestCapability], [RequestPaletteColor], or [Raw]. They're called once
+// when the program starts, before the terminal is set up, so they must return
+// right away. [Program.Run] fails with an error if a command returns
+// anything but a terminal query. Bubble Tea waits at most for the given
+// timeout, which defaults to 100ms when less than 1.
+//
+// Note that the [ColorProfileMsg], [WindowSizeMsg] and
+// [KeyboardEnhancementsMsg] messages sent at startup are also delivered
+// before the first frame.
+//
+// Example:
+//
+//	p := tea.NewProgram(model{}, tea.WithStartupQueries(
+//		200*time.Millisecond,
+//		tea.RequestBackgroundColor,
+//	))
+func WithStartupQueries(timeout time.Duration, queries ...Cmd) ProgramOption {
+	return func(p *Program) {
+		if timeout < 1 {
+			timeout = defaultStartupTimeout
+		}
+		p.startupTimeout = timeout
+		p.startupQueries = append(p.startupQueries, queries...)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestOptions(t *testing.T) {
@@ -94,6 +95,79 @@ func TestOptions(t *testing.T) {
 			})
 		})
 
+		t.Run("capability probe", func(t *testing.T) {
+			t.Parallel()
+			exercise(t, WithCapabilityProbe(0), func(p *Program) {
+				if !p.probeCapabilities || p.probeTimeout != defaultCapabilityProbeTimeout {
+					t.Errorf("expected capability probe with default timeout, got %v", p.probeTimeout)
+				}
+			})
+		})
+
+		t.Run("mouse gestures", func(t *testing.T) {
+			t.Parallel()
+			exercise(t, WithMouseGestures(MouseGestures{}), func(p *Program) {
+				if p.gestures == nil || p.gestures.ClickInterval != defaultClickInterval {
+					t.Errorf("expected mouse gestures with default thresholds, got %+v", p.gestures)
+				}
+			})
+		})
+
+		t.Run("input coalescing", func(t *testing.T) {
+			t.Parallel()
+			exercise(t, WithInputCoalescing(InputCoalescing{MouseMotion: CoalesceLatest}), func(p *Program) {
+				if p.coalescing == nil || p.coalescing.MouseMotion != CoalesceLatest {
+					t.Errorf("expected input coalescing to be enabled, got %+v", p.coalescing)
+				}
+			})
+		})
+
+		t.Run("paste streaming", func(t *testing.T) {
+			t.Parallel()
+			exercise(t, WithPasteStreaming(PasteStreaming{MaxSize: 1024}), func(p *Program) {
+				if p.pasteStreaming == nil || p.pasteStreaming.MaxSize != 1024 {
+					t.Errorf("expected paste streaming to be enabled, got %+v", p.pasteStreaming)
+				}
+			})
+		})
+
+		t.Run("file drops", func(t *testing.T) {
+			t.Parallel()
+			exercise(t, WithFileDrops(), func(p *Program) {
+				if !p.fileDrops {
+					t.Errorf("expected file drops to be enabled")
+				}
+			})
+		})
+
+		t.Run("max concurrent commands", func(t *testing.T) {
+			t.Parallel()
+			exercise(t, WithMaxConcurrentCommands(4), func(p *Program) {
+				if p.commands.max != 4 {
+					t.Errorf("expected at most 4 concurrent commands, got %d", p.commands.max)
+				}
+			})
+		})
+
+		t.Run("command group limit", func(t *testing.T) {
+			t.Parallel()
+			exercise(t, WithCommandGroupLimit("io", 2), func(p *Program) {
+				if g := p.commands.groups["io"]; g == nil || g.max != 2 {
+					t.Errorf("expected the io group to be limited to 2 commands, got %+v", g)
+				}
+			})
+		})
+
+		t.Run("clock", func(t *testing.T) {
+			t.Parallel()
+			clock := NewFakeClock(time.Now())
+			exercise(t, WithClock(clock), func(p *Program) {
+				if p.clock != clock {
+					t.Errorf("expected the fake clock, got %v", p.clock)
+				}
+			})
+		})
+
 		t.Run("without signal handler", func(t *testing.T) {
 			t.Parallel()
 			exercise(t, WithoutSignalHandler(), func(p *Program) {
@@ -0,0 +1,99 @@
+package tea
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// paletteColorMsg is a message that requests a terminal palette color.
+type paletteColorMsg int
+
+// RequestPaletteColor is a command that requests the color of the given
+// terminal palette index using OSC 4. The index is between 0 and 255, where
+// 0-15 are the ANSI colors. The color will be sent as a [PaletteColorMsg].
+//
+// Example:
+//
+//	func (m model) Init() tea.Cmd {
+//	  // What does "red" look like in this terminal?
+//	  return tea.RequestPaletteColor(1)
+//	}
+func RequestPaletteColor(i int) Cmd {
+	return func() Msg {
+		return paletteColorMsg(i)
+	}
+}
+
+// PaletteColorMsg represents a terminal palette color. This message is
+// emitted when the program requests a palette color with the
+// [RequestPaletteColor] Cmd.
+type PaletteColorMsg struct {
+	// Index is the palette index of the color.
+	Index int
+
+	color.Color
+}
+
+// String returns the hex representation of the color.
+func (e PaletteColorMsg) String() string {
+	return uv.ForegroundColorEvent{Color: e.Color}.String()
+}
+
+// IsDark returns whether the color is dark.
+func (e PaletteColorMsg) IsDark() bool {
+	return uv.ForegroundColorEvent{Color: e.Color}.IsDark()
+}
+
+// requestPaletteColor returns the OSC 4 sequence that requests the color of
+// the given palette index.
+func requestPaletteColor(i int) string {
+	return "\x1b]4;" + strconv.Itoa(i) + ";?\x07"
+}
+
+// setPaletteColor returns the OSC 4 sequence that sets the color of the given
+// palette index.
+func setPaletteColor(i int, hex string) string {
+	return "\x1b]4;" + strconv.Itoa(i) + ";" + hex + "\x07"
+}
+
+// resetPaletteColor returns the OSC 104 sequence that resets the color of the
+// given palette index to the terminal's default.
+func resetPaletteColor(i int) string {
+	return "\x1b]104;" + strconv.Itoa(i) + "\x07"
+}
+
+// parsePaletteColor parses an OSC 4 palette color report of the form:
+//
+//	OSC 4 ; index ; color ST
+//
+// It returns false if seq is not a palette color report.
+func parsePaletteColor(seq string) (PaletteColorMsg, bool) {
+	seq = strings.TrimPrefix(seq, "\x1b]")
+	seq = strings.TrimSuffix(strings.TrimSuffix(seq, "\x07"), "\x1b\\")
+	data, ok := strings.CutPrefix(seq, "4;")
+	if !ok {
+		return PaletteColorMsg{}, false
+	}
+	index, spec, ok := strings.Cut(data, ";")
+	if !ok {
+		return PaletteColorMsg{}, false
+	}
+	i, err := strconv.Atoi(index)
+	if err != nil || !validPaletteIndex(i) {
+		return PaletteColorMsg{}, false
+	}
+	c := ansi.XParseColor(spec)
+	if c == nil {
+		return PaletteColorMsg{}, false
+	}
+	return PaletteColorMsg{Index: i, Color: c}, true
+}
+
+// validPaletteIndex returns whether i is a valid 256-color palette index.
+func validPaletteIndex(i int) bool {
+	return i >= 0 && i <= 255 //nolint:mnd
+}
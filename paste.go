@@ -1,5 +1,33 @@
 package tea
 
+import (
+	"bytes"
+	"context"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	// defaultPasteChunkSize is the default maximum size, in bytes, of a
+	// [PasteChunkMsg].
+	defaultPasteChunkSize = 32 * 1024
+
+	// pasteQueueSize is the number of paste messages the paste reader queues
+	// ahead of the event loop.
+	pasteQueueSize = 2
+
+	// pasteReadSize is the size of the paste reader's read buffer.
+	pasteReadSize = 4096
+)
+
+// Bracketed paste delimiters, and the marker the paste reader puts in their
+// place.
+const (
+	pasteStartSeq = "\x1b[200~"
+	pasteEndSeq   = "\x1b[201~"
+	pasteMarker   = "\x1b_bubbletea-paste\x1b\\"
+)
+
 // PasteMsg is an message that is emitted when a terminal receives pasted text
 // using bracketed-paste.
 type PasteMsg struct {
@@ -15,6 +43,253 @@ func (p PasteMsg) String() string {
 // bracketed-paste text.
 type PasteStartMsg struct{}
 
+// PasteChunkMsg is a piece of bracketed-paste text. When [WithPasteStreaming]
+// is enabled, the pasted text is delivered as a series of PasteChunkMsg
+// between a [PasteStartMsg] and a [PasteEndMsg] instead of a [PasteMsg].
+type PasteChunkMsg struct {
+	Content string
+}
+
+// String returns the pasted content as a string.
+func (p PasteChunkMsg) String() string {
+	return p.Content
+}
+
 // PasteEndMsg is an message that is emitted when the terminal ends the
 // bracketed-paste text.
-type PasteEndMsg struct{}
+type PasteEndMsg struct {
+	// Truncated reports whether the pasted text was larger than
+	// [PasteStreaming.MaxSize] and the rest of it was dropped. It's only set
+	// when [WithPasteStreaming] is enabled.
+	Truncated bool
+}
+
+// PasteStreaming configures how pasted text is streamed to the program. Use
+// [WithPasteStreaming] to enable paste streaming in a program.
+//
+// The pasted text is streamed as it's read from the terminal, so that only a
+// few chunks are held in memory at a time. On Windows, the pasted text is
+// read in full before it's split into chunks.
+type PasteStreaming struct {
+	// ChunkSize is the maximum size, in bytes, of a [PasteChunkMsg]. It
+	// defaults to 32KiB.
+	ChunkSize int
+
+	// MaxSize is the maximum size, in bytes, of the pasted text delivered to
+	// the program. Past that, the rest of the text is dropped and
+	// [PasteEndMsg.Truncated] is set. Zero means no limit.
+	MaxSize int
+}
+
+// chunkSize returns the chunk size, or the default if it's not set.
+func (s PasteStreaming) chunkSize() int {
+	if s.ChunkSize <= 0 {
+		return defaultPasteChunkSize
+	}
+	return s.ChunkSize
+}
+
+// split splits the pasted text into chunks. Chunks never split a rune. It
+// also reports whether the text was truncated. It's used when the pasted text
+// couldn't be streamed.
+func (s PasteStreaming) split(content string) (chunks []Msg, truncated bool) {
+	if s.MaxSize > 0 && len(content) > s.MaxSize {
+		content, truncated = content[:runeBoundary(content, s.MaxSize)], true
+	}
+
+	chunkSize := s.chunkSize()
+	for len(content) > 0 {
+		n := len(content)
+		if n > chunkSize {
+			n = runeBoundary(content, chunkSize)
+			if n == 0 {
+				// The chunk size is smaller than the first rune.
+				_, n = utf8.DecodeRuneInString(content)
+			}
+		}
+		chunks = append(chunks, PasteChunkMsg{Content: content[:n]})
+		content = content[n:]
+	}
+	return chunks, truncated
+}
+
+// runeBoundary returns the largest offset of s, no larger than n, that
+// doesn't split a rune.
+func runeBoundary[T string | []byte](s T, n int) int {
+	for n > 0 && n < len(s) && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return n
+}
+
+// pasteMarkerMsg is an internal message that stands for the next message
+// queued by the paste reader.
+type pasteMarkerMsg struct{}
+
+// pasteReader streams bracketed pastes out of the terminal input. It takes
+// the pasted text out of the input as it's read and queues it in chunks. Each
+// queued message is replaced in the input by a marker that the input decoder
+// reports in order with the rest of the input, and the event loop picks the
+// message up when it gets the marker. The queue is bounded, so reading blocks
+// until the program catches up, and the text past
+// [PasteStreaming.MaxSize] is dropped as it's read.
+type pasteReader struct {
+	r    io.Reader
+	s    PasteStreaming
+	msgs chan Msg
+	ctx  context.Context
+
+	buf []byte // read buffer
+	in  []byte // input that wasn't processed yet
+	out []byte // processed input for the decoder
+	err error  // read error, returned once the input is processed
+
+	pasting   bool
+	text      []byte // pasted text that wasn't queued yet
+	size      int    // size of the pasted text kept so far
+	truncated bool
+}
+
+// newPasteReader returns a paste reader that reads the input from r and
+// queues the paste messages in msgs.
+func newPasteReader(ctx context.Context, r io.Reader, s PasteStreaming, msgs chan Msg) *pasteReader {
+	return &pasteReader{r: r, s: s, msgs: msgs, ctx: ctx, buf: make([]byte, pasteReadSize)}
+}
+
+// Read implements [io.Reader].
+func (r *pasteReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		progress, err := r.process()
+		if err != nil {
+			return 0, err
+		}
+		if progress {
+			continue
+		}
+		if r.err != nil {
+			// Whatever is left can't be part of a paste anymore.
+			if !r.pasting && len(r.in) > 0 {
+				r.out, r.in = r.in, nil
+				break
+			}
+			return 0, r.err
+		}
+		n, err := r.r.Read(r.buf)
+		r.in = append(r.in, r.buf[:n]...)
+		r.err = err
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// process processes the pending input until it queues a paste message, so
+// that its marker reaches the decoder before we wait for the queue again. It
+// reports whether it made any progress.
+func (r *pasteReader) process() (bool, error) {
+	if r.pasting {
+		if chunk := r.chunk(false); chunk != nil {
+			return true, r.queue(chunk)
+		}
+	}
+
+	var progress bool
+	for len(r.in) > 0 {
+		if !r.pasting {
+			i := bytes.Index(r.in, []byte(pasteStartSeq))
+			if i < 0 {
+				// Hold back what might be the beginning of a paste. An
+				// escape, or an escape and a bracket, are passed on since
+				// they could be key presses the decoder has to see now.
+				k := partialSuffix(r.in, pasteStartSeq)
+				if k < 3 { //nolint:mnd
+					k = 0
+				}
+				r.out = append(r.out, r.in[:len(r.in)-k]...)
+				r.in = append(r.in[:0], r.in[len(r.in)-k:]...)
+				return progress || len(r.out) > 0, nil
+			}
+			r.out = append(r.out, r.in[:i]...)
+			r.in = r.in[i+len(pasteStartSeq):]
+			r.pasting, r.size, r.truncated = true, 0, false
+			return true, r.queue(PasteStartMsg{})
+		}
+
+		end := bytes.Index(r.in, []byte(pasteEndSeq))
+		n := end
+		if end < 0 {
+			n = len(r.in) - partialSuffix(r.in, pasteEndSeq)
+		}
+		r.add(r.in[:n])
+		r.in = r.in[n:]
+		progress = progress || n > 0
+
+		if chunk := r.chunk(end >= 0); chunk != nil {
+			return true, r.queue(chunk)
+		}
+		if end < 0 {
+			return progress, nil
+		}
+		r.in = r.in[len(pasteEndSeq):]
+		r.pasting = false
+		return true, r.queue(PasteEndMsg{Truncated: r.truncated})
+	}
+	return progress, nil
+}
+
+// add adds pasted text, dropping what doesn't fit in
+// [PasteStreaming.MaxSize].
+func (r *pasteReader) add(text []byte) {
+	if r.truncated {
+		return
+	}
+	if limit := r.s.MaxSize; limit > 0 && r.size+len(text) > limit {
+		text = text[:runeBoundary(text, limit-r.size)]
+		r.truncated = true
+	}
+	r.size += len(text)
+	r.text = append(r.text, text...)
+}
+
+// chunk returns the next chunk of pasted text, if there's enough text for a
+// chunk or the paste ended.
+func (r *pasteReader) chunk(ended bool) Msg {
+	chunkSize := r.s.chunkSize()
+	n := len(r.text)
+	switch {
+	case n > chunkSize:
+		n = runeBoundary(r.text, chunkSize)
+		if n == 0 {
+			// The chunk size is smaller than the first rune.
+			_, n = utf8.DecodeRune(r.text)
+		}
+	case n == 0 || !ended:
+		return nil
+	}
+	chunk := PasteChunkMsg{Content: string(r.text[:n])}
+	r.text = append(r.text[:0], r.text[n:]...)
+	return chunk
+}
+
+// queue queues msg for the event loop and writes its marker for the decoder.
+// It blocks while the queue is full.
+func (r *pasteReader) queue(msg Msg) error {
+	select {
+	case <-r.ctx.Done():
+		return r.ctx.Err() //nolint:wrapcheck
+	case r.msgs <- msg:
+	}
+	r.out = append(r.out, pasteMarker...)
+	return nil
+}
+
+// partialSuffix returns the length of the longest suffix of b that is a
+// proper prefix of seq.
+func partialSuffix(b []byte, seq string) int {
+	for n := min(len(b), len(seq)-1); n > 0; n-- {
+		if string(b[len(b)-n:]) == seq[:n] {
+			return n
+		}
+	}
+	return 0
+}
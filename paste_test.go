@@ -0,0 +1,235 @@
+package tea
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPasteStreamingSplit(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name      string
+		streaming PasteStreaming
+		content   string
+		chunks    []string
+		truncated bool
+	}{
+		{
+			name:    "single chunk",
+			content: "hello",
+			chunks:  []string{"hello"},
+		},
+		{
+			name:      "chunks",
+			streaming: PasteStreaming{ChunkSize: 2},
+			content:   "hello",
+			chunks:    []string{"he", "ll", "o"},
+		},
+		{
+			name:      "runes",
+			streaming: PasteStreaming{ChunkSize: 3},
+			content:   "héllo wörld",
+			chunks:    []string{"hé", "llo", " w", "ör", "ld"},
+		},
+		{
+			name:      "chunk smaller than a rune",
+			streaming: PasteStreaming{ChunkSize: 1},
+			content:   "😀é",
+			chunks:    []string{"😀", "é"},
+		},
+		{
+			name:      "truncated",
+			streaming: PasteStreaming{ChunkSize: 4, MaxSize: 6},
+			content:   "hello world",
+			chunks:    []string{"hell", "o "},
+			truncated: true,
+		},
+		{
+			name:      "truncated rune",
+			streaming: PasteStreaming{MaxSize: 2},
+			content:   "hé",
+			chunks:    []string{"h"},
+			truncated: true,
+		},
+		{
+			name:      "max size",
+			streaming: PasteStreaming{MaxSize: 5},
+			content:   "hello",
+			chunks:    []string{"hello"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			chunks, truncated := tc.streaming.split(tc.content)
+			var got []string
+			for _, chunk := range chunks {
+				got = append(got, chunk.(PasteChunkMsg).Content)
+			}
+			if !reflect.DeepEqual(got, tc.chunks) {
+				t.Errorf("expected chunks %q, got %q", tc.chunks, got)
+			}
+			if truncated != tc.truncated {
+				t.Errorf("expected truncated to be %v, got %v", tc.truncated, truncated)
+			}
+		})
+	}
+}
+
+type pasteModel struct {
+	msgs chan Msg
+}
+
+func (m pasteModel) Init() Cmd { return nil }
+
+func (m pasteModel) Update(msg Msg) (Model, Cmd) {
+	switch msg := msg.(type) {
+	case PasteStartMsg, PasteChunkMsg, PasteMsg, PasteEndMsg:
+		m.msgs <- msg
+	case KeyPressMsg:
+		if msg.String() == "q" {
+			return m, Quit
+		}
+	}
+	return m, nil
+}
+
+func (m pasteModel) View() View { return NewView("paste") }
+
+func TestPasteStreaming(t *testing.T) {
+	t.Parallel()
+
+	pr, pw := io.Pipe()
+	defer pw.Close() //nolint:errcheck
+
+	m := pasteModel{msgs: make(chan Msg, 10)}
+	p := NewProgram(m,
+		WithContext(t.Context()),
+		WithInput(pr),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+		WithPasteStreaming(PasteStreaming{ChunkSize: 4, MaxSize: 10}),
+	)
+
+	go func() {
+		_, _ = io.WriteString(pw, "\x1b[200~"+strings.Repeat("ab", 10)+"\x1b[201~q")
+	}()
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	close(m.msgs)
+
+	var got []Msg
+	for msg := range m.msgs {
+		got = append(got, msg)
+	}
+	want := []Msg{
+		PasteStartMsg{},
+		PasteChunkMsg{Content: "abab"},
+		PasteChunkMsg{Content: "abab"},
+		PasteChunkMsg{Content: "ab"},
+		PasteEndMsg{Truncated: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+// piecesReader returns the given pieces, one per read.
+type piecesReader struct {
+	pieces []string
+}
+
+func (r *piecesReader) Read(p []byte) (int, error) {
+	if len(r.pieces) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.pieces[0])
+	r.pieces[0] = r.pieces[0][n:]
+	if len(r.pieces[0]) == 0 {
+		r.pieces = r.pieces[1:]
+	}
+	return n, nil
+}
+
+func TestPasteReader(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name      string
+		streaming PasteStreaming
+		pieces    []string
+		out       string
+		msgs      []Msg
+	}{
+		{
+			name:   "no paste",
+			pieces: []string{"ab\x1b", "[A"},
+			out:    "ab\x1b[A",
+		},
+		{
+			name:      "split delimiters",
+			streaming: PasteStreaming{ChunkSize: 4},
+			pieces:    []string{"a\x1b[20", "0~hél", "lo wo", "rld\x1b[2", "01~b"},
+			out:       "a" + strings.Repeat(pasteMarker, 5) + "b",
+			msgs: []Msg{
+				PasteStartMsg{},
+				PasteChunkMsg{Content: "hél"},
+				PasteChunkMsg{Content: "lo w"},
+				PasteChunkMsg{Content: "orld"},
+				PasteEndMsg{},
+			},
+		},
+		{
+			name:      "truncated",
+			streaming: PasteStreaming{ChunkSize: 2, MaxSize: 5},
+			pieces:    []string{"\x1b[200~" + strings.Repeat("x", 1000), strings.Repeat("x", 1000) + "\x1b[201~"},
+			out:       strings.Repeat(pasteMarker, 5),
+			msgs: []Msg{
+				PasteStartMsg{},
+				PasteChunkMsg{Content: "xx"},
+				PasteChunkMsg{Content: "xx"},
+				PasteChunkMsg{Content: "x"},
+				PasteEndMsg{Truncated: true},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			msgs := make(chan Msg, pasteQueueSize)
+			r := newPasteReader(t.Context(), &piecesReader{pieces: tc.pieces}, tc.streaming, msgs)
+
+			var got []Msg
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for msg := range msgs {
+					got = append(got, msg)
+				}
+			}()
+
+			out, err := io.ReadAll(r)
+			close(msgs)
+			<-done
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tc.out {
+				t.Errorf("expected output %q, got %q", tc.out, out)
+			}
+			if !reflect.DeepEqual(got, tc.msgs) {
+				t.Errorf("expected %v, got %v", tc.msgs, got)
+			}
+			if len(r.text) > 0 {
+				t.Errorf("expected no pasted text to be held, got %d bytes", len(r.text))
+			}
+			if limit := tc.streaming.MaxSize; limit > 0 && r.size > limit {
+				t.Errorf("expected at most %d bytes to be kept, got %d", limit, r.size)
+			}
+		})
+	}
+}
@@ -54,6 +54,27 @@ type renderer interface {
 
 	// onMouse handles a mouse event.
 	onMouse(MouseMsg) Cmd
+
+	// requestCursorPosition requests the cursor position on behalf of the
+	// program, so that the report isn't mistaken for the renderer's. It
+	// reports whether the request was sent.
+	requestCursorPosition() bool
+
+	// cursorPosition handles a cursor position report. It reports whether
+	// the report answers a request of the renderer. An ambiguous report,
+	// which may be a key press, never does.
+	cursorPosition(x, y int, ambiguous bool) bool
+
+	// frameOrigin returns the terminal row where the frame starts, and
+	// whether it's known.
+	frameOrigin() (int, bool)
+
+	// mousePixelsReported handles a report of the pixel mouse mode.
+	mousePixelsReported()
+
+	// mousePixelsPending reports whether the renderer changed the pixel
+	// mouse mode and the terminal hasn't reported it yet.
+	mousePixelsPending() bool
 }
 
 type printLineMessage struct {
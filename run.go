@@ -0,0 +1,53 @@
+package tea
+
+import (
+	"fmt"
+)
+
+// RunModel runs a program with the given model and options, and returns the
+// final model as its concrete type. It saves a type assertion on the model
+// returned by [Program.Run]:
+//
+//	m, err := tea.RunModel(model{})
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(m.choice)
+//
+// Like [Program.Run], the final model is returned along with the error when
+// the program is killed. If Update returned a model of another type, the
+// zero value of M is returned along with an error.
+func RunModel[M Model](m M, opts ...ProgramOption) (M, error) {
+	final, err := NewProgram(m, opts...).Run()
+	if final == nil {
+		var zero M
+		return zero, err
+	}
+	typed, ok := final.(M)
+	if !ok {
+		var zero M
+		if err == nil {
+			err = fmt.Errorf("bubbletea: expected the final model to be a %T, got a %T", zero, final)
+		}
+		return zero, err
+	}
+	return typed, err
+}
+
+// RunModelResult runs a program like [RunModel], and returns the result
+// extracted from the final model by result:
+//
+//	choice, err := tea.RunModelResult(model{}, func(m model) string {
+//	    return m.choice
+//	})
+//
+// When the program fails, the zero value of R is returned along with the
+// error.
+func RunModelResult[M Model, R any](m M, result func(M) R, opts ...ProgramOption) (R, error) {
+	final, err := RunModel(m, opts...)
+	if err != nil {
+		var zero R
+		return zero, err
+	}
+	return result(final), nil
+}
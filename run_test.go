@@ -0,0 +1,73 @@
+package tea
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+type runModel struct {
+	choice string
+	swap   bool
+}
+
+func (m runModel) Init() Cmd {
+	return func() Msg { return "pizza" }
+}
+
+func (m runModel) Update(msg Msg) (Model, Cmd) {
+	if choice, ok := msg.(string); ok {
+		m.choice = choice
+		if m.swap {
+			return &m, Quit
+		}
+		return m, Quit
+	}
+	return m, nil
+}
+
+func (m runModel) View() View { return NewView(m.choice) }
+
+func runModelOptions(t *testing.T) []ProgramOption {
+	return []ProgramOption{
+		WithContext(t.Context()),
+		WithInput(nil),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+	}
+}
+
+func TestRunModel(t *testing.T) {
+	t.Parallel()
+
+	m, err := RunModel(runModel{}, runModelOptions(t)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.choice != "pizza" {
+		t.Errorf("expected pizza, got %q", m.choice)
+	}
+}
+
+func TestRunModelType(t *testing.T) {
+	t.Parallel()
+
+	_, err := RunModel(runModel{swap: true}, runModelOptions(t)...)
+	if err == nil || !strings.Contains(err.Error(), "expected the final model to be a tea.runModel, got a *tea.runModel") {
+		t.Errorf("expected a model type error, got %v", err)
+	}
+}
+
+func TestRunModelResult(t *testing.T) {
+	t.Parallel()
+
+	choice, err := RunModelResult(runModel{}, func(m runModel) string {
+		return m.choice
+	}, runModelOptions(t)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if choice != "pizza" {
+		t.Errorf("expected pizza, got %q", choice)
+	}
+}
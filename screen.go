@@ -9,6 +9,13 @@ type WindowSizeMsg struct {
 	Height int
 }
 
+// CellSizeMsg reports the size of a terminal cell in pixels. Bubble Tea
+// requests it when [View.MousePixels] is enabled.
+type CellSizeMsg struct {
+	Width  int
+	Height int
+}
+
 // ClearScreen is a special command that tells the program to clear the screen
 // before the next update. This can be used to move the cursor to the top left
 // of the screen and clear visual clutter when the alt screen is not in use.
@@ -0,0 +1,94 @@
+package tea
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// defaultStartupTimeout is the default amount of time to wait for the
+// terminal to answer the startup queries.
+const defaultStartupTimeout = 100 * time.Millisecond
+
+// querySequence returns the escape sequence for the given terminal query
+// message, or an empty string if msg is not a query.
+func querySequence(msg Msg) string {
+	switch msg := msg.(type) {
+	case backgroundColorMsg:
+		return ansi.RequestBackgroundColor
+	case foregroundColorMsg:
+		return ansi.RequestForegroundColor
+	case cursorColorMsg:
+		return ansi.RequestCursorColor
+	case terminalVersion:
+		return ansi.RequestNameVersion
+	case requestCapabilityMsg:
+		return ansi.RequestTermcap(string(msg))
+	case requestCursorPosMsg:
+		return ansi.RequestCursorPositionReport
+	case paletteColorMsg:
+		return requestPaletteColor(int(msg))
+	case RawMsg:
+		return fmt.Sprint(msg.Msg)
+	}
+	return ""
+}
+
+// startupQuerySequence returns the escape sequences of the given startup
+// queries. Queries are commands that return a terminal query message, and
+// anything else is an error.
+func startupQuerySequence(queries []Cmd) (string, error) {
+	var seq string
+	for _, query := range queries {
+		if query == nil {
+			continue
+		}
+		msg := query()
+		s := querySequence(msg)
+		if s == "" {
+			return "", fmt.Errorf("bubbletea: startup query returned %T, which is not a terminal query", msg)
+		}
+		seq += s
+	}
+	return seq, nil
+}
+
+// awaitStartupQueries sends the startup queries seq to the terminal and waits
+// until it answers them, or the startup timeout elapses. The primary device
+// attributes request is sent last: every terminal answers it, and since
+// terminals answer in order, its reply means all the other replies have
+// arrived.
+//
+// All messages received in the meantime are kept in [Program.startupMsgs] so
+// that the event loop can handle them before rendering the first frame.
+func (p *Program) awaitStartupQueries(seq string) {
+	// Flush the renderer first so that its keyboard enhancements query is
+	// sent, and thus answered, before our sentinel.
+	_ = p.renderer.flush(false)
+	p.execute(seq + ansi.RequestPrimaryDeviceAttributes)
+	_ = p.flush()
+
+	// The capability probe has its own sentinel that will be answered first.
+	sentinels := 1
+	if p.probing {
+		sentinels++
+	}
+
+	timeout, timer := after(p.clock, p.startupTimeout)
+	defer timer.Stop()
+
+	for sentinels > 0 {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-timeout:
+			return
+		case msg := <-p.msgs:
+			p.startupMsgs = append(p.startupMsgs, msg)
+			if _, ok := p.translateInputEvent(msg).(PrimaryDeviceAttributesMsg); ok {
+				sentinels--
+			}
+		}
+	}
+}
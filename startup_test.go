@@ -0,0 +1,113 @@
+package tea
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+type startupModel struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (m *startupModel) record(s string) {
+	m.mu.Lock()
+	m.events = append(m.events, s)
+	m.mu.Unlock()
+}
+
+func (m *startupModel) Init() Cmd {
+	m.record("init")
+	return nil
+}
+
+func (m *startupModel) Update(msg Msg) (Model, Cmd) {
+	switch msg := msg.(type) {
+	case BackgroundColorMsg:
+		m.record("bg:" + msg.String())
+	case KeyPressMsg:
+		if msg.String() == "q" {
+			return m, Quit
+		}
+	}
+	return m, nil
+}
+
+func (m *startupModel) View() View {
+	m.record("view")
+	return NewView("hello")
+}
+
+func TestStartupQueries(t *testing.T) {
+	t.Parallel()
+
+	pr, pw := io.Pipe()
+	defer pw.Close() //nolint:errcheck
+
+	var out safeBuffer
+	m := &startupModel{}
+	p := NewProgram(m,
+		WithContext(t.Context()),
+		WithInput(pr),
+		WithOutput(&out),
+		WithoutSignals(),
+		WithStartupQueries(5*time.Second, RequestBackgroundColor),
+	)
+
+	go func() {
+		// The terminal answers the background color query, then the
+		// primary device attributes sentinel.
+		_, _ = io.WriteString(pw, "\x1b]11;rgb:ffff/ffff/ffff\x1b\\\x1b[?62;22c")
+		time.Sleep(50 * time.Millisecond)
+		_, _ = io.WriteString(pw, "q")
+	}()
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.events) < 3 || m.events[0] != "init" || m.events[1] != "bg:#ffffff" || m.events[2] != "view" {
+		t.Fatalf("expected init, background color, then view, got %v", m.events)
+	}
+	assertInOrder(t, out.String(), ansi.RequestBackgroundColor, ansi.RequestPrimaryDeviceAttributes)
+}
+
+// safeBuffer is a strings.Builder that is safe for concurrent use.
+type safeBuffer struct {
+	mu sync.Mutex
+	sb strings.Builder
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.Write(p) //nolint:wrapcheck
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.String()
+}
+
+func TestStartupQueriesNotAQuery(t *testing.T) {
+	t.Parallel()
+
+	p := NewProgram(&startupModel{},
+		WithContext(t.Context()),
+		WithInput(nil),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+		WithStartupQueries(0, RequestBackgroundColor, Quit),
+	)
+	if _, err := p.Run(); err == nil || !strings.Contains(err.Error(), "tea.QuitMsg") {
+		t.Fatalf("expected an error about the quit command, got %v", err)
+	}
+}
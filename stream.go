@@ -0,0 +1,125 @@
+package tea
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// StreamDoneMsg is sent when a stream started with [Stream], [FromChannel], or
+// [FromSeq] ends. It's not sent when the stream is cancelled because the
+// program exits.
+type StreamDoneMsg struct {
+	// ID is the ID the stream was started with, which tells streams apart.
+	ID string
+
+	// Err is the error the stream ended with, if any.
+	Err error
+}
+
+// streamMsg is used internally to run a stream that emits messages until it
+// returns or the program exits.
+type streamMsg struct {
+	id string
+	fn func(ctx context.Context, emit func(Msg)) error
+}
+
+// Stream returns a command that runs fn and sends the messages it emits, in
+// order, while it runs. When fn returns, a [StreamDoneMsg] holding id and the
+// error of fn is sent. The context is cancelled when the program exits, and
+// fn must return then. Use it to report the progress of a long job:
+//
+//	func download(url string) tea.Cmd {
+//	    return tea.Stream(url, func(ctx context.Context, emit func(tea.Msg)) error {
+//	        for i := range 100 {
+//	            if err := fetchPart(ctx, url, i); err != nil {
+//	                return err
+//	            }
+//	            emit(progressMsg(i + 1))
+//	        }
+//	        return nil
+//	    })
+//	}
+//
+// Like other commands, streams run in their own goroutine, and Sequence waits
+// for a stream to end before running the next command. If fn panics, the
+// stream ends and the StreamDoneMsg holds an error that describes the panic.
+func Stream(id string, fn func(ctx context.Context, emit func(Msg)) error) Cmd {
+	if fn == nil {
+		return nil
+	}
+	return func() Msg {
+		return streamMsg{id: id, fn: fn}
+	}
+}
+
+// FromChannel returns a command that sends every value received from ch as a
+// message, in order, until ch is closed or the program exits. Once ch is
+// closed, a [StreamDoneMsg] holding id is sent.
+//
+//	func (m model) Init() tea.Cmd {
+//	    return tea.FromChannel("events", m.events)
+//	}
+//
+// Unlike a command that receives a single value, it doesn't need to be
+// returned again after each message.
+func FromChannel[T any](id string, ch <-chan T) Cmd {
+	return Stream(id, func(ctx context.Context, emit func(Msg)) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case v, ok := <-ch:
+				if !ok {
+					return nil
+				}
+				emit(v)
+			}
+		}
+	})
+}
+
+// FromSeq returns a command that sends every message yielded by seq, in
+// order, until seq is exhausted or the program exits. Once seq is exhausted, a
+// [StreamDoneMsg] holding id is sent. If seq panics, the StreamDoneMsg holds
+// an error that describes the panic.
+func FromSeq(id string, seq iter.Seq[Msg]) Cmd {
+	return Stream(id, func(ctx context.Context, emit func(Msg)) error {
+		for msg := range seq {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			emit(msg)
+		}
+		return nil
+	})
+}
+
+// execStreamMsg runs a stream until it ends, sending the messages it emits
+// and a final [StreamDoneMsg].
+func (p *Program) execStreamMsg(msg streamMsg) {
+	err := p.runStream(msg)
+	if p.ctx.Err() != nil {
+		// The program exited, nobody is listening.
+		return
+	}
+	p.Send(StreamDoneMsg{ID: msg.id, Err: err})
+}
+
+// runStream runs the function of a stream and returns its error. A panic in
+// the function ends the stream with an error, unless panics aren't caught.
+func (p *Program) runStream(msg streamMsg) (err error) {
+	if !p.disableCatchPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("bubbletea: stream %q panicked: %v", msg.id, r)
+			}
+		}()
+	}
+
+	return msg.fn(p.ctx, func(msg Msg) {
+		if msg != nil {
+			p.Send(msg)
+		}
+	})
+}
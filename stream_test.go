@@ -0,0 +1,169 @@
+package tea
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type streamModel struct {
+	cmd  Cmd
+	msgs []Msg
+}
+
+func (m *streamModel) Init() Cmd { return m.cmd }
+
+func (m *streamModel) Update(msg Msg) (Model, Cmd) {
+	switch msg := msg.(type) {
+	case int, string, StreamDoneMsg, SequenceStoppedMsg:
+		m.msgs = append(m.msgs, msg)
+	}
+	return m, nil
+}
+
+func (m *streamModel) View() View { return NewView("stream") }
+
+func runStream(t *testing.T, cmd Cmd) (*streamModel, error) {
+	t.Helper()
+	var in, out bytes.Buffer
+	m := &streamModel{cmd: Sequence(cmd, Quit)}
+	p := NewProgram(m,
+		WithContext(t.Context()),
+		WithInput(&in),
+		WithOutput(&out),
+		WithoutSignals(),
+	)
+	_, err := p.Run()
+	return m, err
+}
+
+func TestFromChannel(t *testing.T) {
+	t.Parallel()
+
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+
+	m, err := runStream(t, FromChannel("numbers", ch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Msg{1, 2, 3, StreamDoneMsg{ID: "numbers"}}; !reflect.DeepEqual(m.msgs, want) {
+		t.Errorf("expected %v, got %v", want, m.msgs)
+	}
+}
+
+func TestFromSeq(t *testing.T) {
+	t.Parallel()
+
+	// The sequence waits for the stream to end before running the next
+	// command.
+	cmd := Sequence(
+		FromSeq("letters", slices.Values([]Msg{"a", nil, "b"})),
+		func() Msg { return "c" },
+	)
+	m, err := runStream(t, cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Msg{"a", "b", StreamDoneMsg{ID: "letters"}, "c"}; !reflect.DeepEqual(m.msgs, want) {
+		t.Errorf("expected %v, got %v", want, m.msgs)
+	}
+}
+
+func TestFromSeqPanic(t *testing.T) {
+	t.Parallel()
+
+	m, err := runStream(t, FromSeq("panic", func(yield func(Msg) bool) {
+		yield("before")
+		panic("testing panic behavior")
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.msgs) != 2 || m.msgs[0] != "before" {
+		t.Fatalf("expected a message and the end of the stream, got %v", m.msgs)
+	}
+	done, ok := m.msgs[1].(StreamDoneMsg)
+	if !ok || done.ID != "panic" || done.Err == nil || !strings.Contains(done.Err.Error(), "testing panic behavior") {
+		t.Errorf("expected the stream to end with the panic, got %#v", m.msgs[1])
+	}
+}
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	errFailed := errors.New("failed")
+	m, err := runStream(t, Stream("job", func(_ context.Context, emit func(Msg)) error {
+		for i := range 3 {
+			emit(i)
+		}
+		return errFailed
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Msg{0, 1, 2, StreamDoneMsg{ID: "job", Err: errFailed}}; !reflect.DeepEqual(m.msgs, want) {
+		t.Errorf("expected %v, got %v", want, m.msgs)
+	}
+}
+
+func TestStreamIDs(t *testing.T) {
+	t.Parallel()
+
+	m, err := runStream(t, Batch(
+		FromSeq("a", slices.Values([]Msg{1})),
+		FromSeq("b", slices.Values([]Msg{2})),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := map[string]bool{}
+	for _, msg := range m.msgs {
+		if msg, ok := msg.(StreamDoneMsg); ok {
+			done[msg.ID] = true
+		}
+	}
+	if !done["a"] || !done["b"] || len(done) != 2 {
+		t.Errorf("expected both streams to report their end, got %v", m.msgs)
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	t.Parallel()
+
+	started, done := make(chan struct{}), make(chan struct{})
+	m := &streamModel{cmd: Stream("wait", func(ctx context.Context, _ func(Msg)) error {
+		defer close(done)
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})}
+	var in, out bytes.Buffer
+	p := NewProgram(m,
+		WithContext(t.Context()),
+		WithInput(&in),
+		WithOutput(&out),
+		WithoutSignals(),
+	)
+	go func() {
+		<-started
+		p.Quit()
+	}()
+
+	if _, err := p.Run(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the stream to be cancelled")
+	}
+}
@@ -0,0 +1,212 @@
+package tea
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// Sub is a subscription, a long-running source of messages like a timer, a
+// channel, or a signal listener. Subscriptions are declared by models that
+// implement [Subscriber].
+type Sub struct {
+	// Key identifies the subscription across updates. While a model keeps
+	// returning a subscription with the same key, the subscription keeps
+	// running. To restart a subscription with different parameters, change
+	// its key.
+	Key string
+
+	// Run runs the subscription. It sends messages to the program with send
+	// and must return once ctx is done.
+	Run func(ctx context.Context, send func(Msg))
+}
+
+// Subscriber is an optional interface for models that declare their
+// subscriptions. After [Model.Init] and every [Model.Update], Bubble Tea calls
+// Subscriptions and compares the result with the running subscriptions by
+// key. New subscriptions are started and the ones that are gone are
+// cancelled. All subscriptions are cancelled when the program exits.
+//
+//	func (m model) Subscriptions() []tea.Sub {
+//	    if m.paused {
+//	        return nil
+//	    }
+//	    return []tea.Sub{
+//	        tea.IntervalSub("tick", time.Second, func(t time.Time) tea.Msg {
+//	            return tickMsg(t)
+//	        }),
+//	    }
+//	}
+type Subscriber interface {
+	Subscriptions() []Sub
+}
+
+// IntervalSub returns a subscription that sends the message returned by fn
+// every d, until it's cancelled. Unlike [Tick], it doesn't have to be
+// returned again after each message.
+func IntervalSub(key string, d time.Duration, fn func(time.Time) Msg) Sub {
+	return Sub{
+		Key: key,
+		Run: func(ctx context.Context, send func(Msg)) {
+			clock := clockFromContext(ctx)
+			next := clock.Now()
+			for {
+				// Skip the ticks we missed while sending.
+				now := clock.Now()
+				if next = next.Add(d); !next.After(now) {
+					next = now.Add(d)
+				}
+				tick, timer := after(clock, next.Sub(now))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case now := <-tick:
+					send(fn(now))
+				}
+			}
+		},
+	}
+}
+
+// ChannelSub returns a subscription that sends the message returned by fn for
+// every value received from ch, until ch is closed or the subscription is
+// cancelled.
+func ChannelSub[T any](key string, ch <-chan T, fn func(T) Msg) Sub {
+	return Sub{
+		Key: key,
+		Run: func(ctx context.Context, send func(Msg)) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case v, ok := <-ch:
+					if !ok {
+						return
+					}
+					send(fn(v))
+				}
+			}
+		},
+	}
+}
+
+// SignalSub returns a subscription that sends the message returned by fn
+// every time the process receives one of the given signals, until it's
+// cancelled. See [signal.Notify] for details on the signals.
+func SignalSub(key string, fn func(os.Signal) Msg, sigs ...os.Signal) Sub {
+	return Sub{
+		Key: key,
+		Run: func(ctx context.Context, send func(Msg)) {
+			ch := make(chan os.Signal, 1)
+			signal.Notify(ch, sigs...)
+			defer signal.Stop(ch)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case sig := <-ch:
+					send(fn(sig))
+				}
+			}
+		},
+	}
+}
+
+// subscriptions manages the running subscriptions of a program.
+type subscriptions struct {
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// updateSubscriptions starts the subscriptions of the model that aren't running yet and
+// cancels the running ones the model no longer declares.
+func (p *Program) updateSubscriptions(model Model) {
+	var subs []Sub
+	if s, ok := model.(Subscriber); ok {
+		subs = s.Subscriptions()
+	}
+
+	p.subs.mu.Lock()
+	defer p.subs.mu.Unlock()
+	if p.subs.stopped || (len(subs) == 0 && len(p.subs.running) == 0) {
+		return
+	}
+	if p.subs.running == nil {
+		p.subs.running = make(map[string]context.CancelFunc)
+	}
+
+	keep := make(map[string]bool, len(subs))
+	for _, sub := range subs {
+		if keep[sub.Key] || sub.Run == nil {
+			continue
+		}
+		keep[sub.Key] = true
+		if _, ok := p.subs.running[sub.Key]; ok {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.WithValue(p.ctx, clockKey{}, p.clock))
+		p.subs.running[sub.Key] = cancel
+		p.subs.wg.Add(1)
+		go p.runSubscription(ctx, sub)
+	}
+
+	for key, cancel := range p.subs.running {
+		if !keep[key] {
+			cancel()
+			delete(p.subs.running, key)
+		}
+	}
+}
+
+// runSubscription runs a subscription until it returns.
+func (p *Program) runSubscription(ctx context.Context, sub Sub) {
+	// Recover from panics.
+	if !p.disableCatchPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				p.recoverFromPanic(r)
+			}
+		}()
+	}
+	// Done before recovering from panics, which waits for the subscriptions
+	// to stop.
+	defer p.subs.wg.Done()
+
+	sub.Run(ctx, func(msg Msg) {
+		if msg == nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+		case p.msgs <- msg:
+		}
+	})
+}
+
+// handleSubscriptions cancels the subscriptions when the program exits and
+// waits for them to stop.
+func (p *Program) handleSubscriptions() chan struct{} {
+	ch := make(chan struct{})
+
+	go func() {
+		defer close(ch)
+
+		<-p.ctx.Done()
+		p.subs.mu.Lock()
+		p.subs.stopped = true
+		for _, cancel := range p.subs.running {
+			cancel()
+		}
+		p.subs.running = nil
+		p.subs.mu.Unlock()
+		p.subs.wg.Wait()
+	}()
+
+	return ch
+}
@@ -0,0 +1,123 @@
+package tea
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type subModel struct {
+	ticks  int
+	values chan int
+	sum    int
+}
+
+type subTickMsg struct{}
+
+type subValueMsg int
+
+func (m subModel) Init() Cmd { return nil }
+
+func (m subModel) Update(msg Msg) (Model, Cmd) {
+	switch msg := msg.(type) {
+	case subTickMsg:
+		m.ticks++
+		if m.ticks == 3 {
+			// Stop ticking and start listening to the channel.
+			go func() {
+				for i := 1; i <= 4; i++ {
+					m.values <- i
+				}
+			}()
+		}
+	case subValueMsg:
+		m.sum += int(msg)
+		if m.sum == 10 {
+			return m, Quit
+		}
+	}
+	return m, nil
+}
+
+func (m subModel) View() View { return NewView("subscriptions") }
+
+func (m subModel) Subscriptions() []Sub {
+	if m.ticks < 3 {
+		return []Sub{IntervalSub("tick", time.Millisecond, func(time.Time) Msg {
+			return subTickMsg{}
+		})}
+	}
+	return []Sub{ChannelSub("values", m.values, func(v int) Msg {
+		return subValueMsg(v)
+	})}
+}
+
+func TestSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	m := subModel{values: make(chan int)}
+	p := NewProgram(m,
+		WithContext(t.Context()),
+		WithInput(nil),
+		WithOutput(io.Discard),
+		WithoutSignals(),
+	)
+
+	model, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := model.(subModel); got.ticks != 3 || got.sum != 10 {
+		t.Errorf("expected 3 ticks and a sum of 10, got %d ticks and a sum of %d", got.ticks, got.sum)
+	}
+}
+
+type keyedSubsModel struct {
+	subs []Sub
+}
+
+func (m keyedSubsModel) Init() Cmd               { return nil }
+func (m keyedSubsModel) Update(Msg) (Model, Cmd) { return m, nil }
+func (m keyedSubsModel) View() View              { return View{} }
+func (m keyedSubsModel) Subscriptions() []Sub    { return m.subs }
+
+func TestUpdateSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	p := NewProgram(nil)
+	p.ctx = t.Context()
+
+	var starts, running atomic.Int32
+	sub := func(key string) Sub {
+		return Sub{Key: key, Run: func(ctx context.Context, _ func(Msg)) {
+			starts.Add(1)
+			running.Add(1)
+			defer running.Add(-1)
+			<-ctx.Done()
+		}}
+	}
+	wait := func(wantStarts, wantRunning int32) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for starts.Load() != wantStarts || running.Load() != wantRunning {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %d started and %d running subscriptions, got %d and %d",
+					wantStarts, wantRunning, starts.Load(), running.Load())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	p.updateSubscriptions(keyedSubsModel{subs: []Sub{sub("a"), sub("b"), sub("b")}})
+	wait(2, 2)
+
+	// Keeping "b" doesn't restart it, and "a" is cancelled.
+	p.updateSubscriptions(keyedSubsModel{subs: []Sub{sub("b"), sub("c")}})
+	wait(3, 2)
+
+	// Models that aren't subscribers cancel everything.
+	p.updateSubscriptions(&testModel{})
+	wait(3, 0)
+}
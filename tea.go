@@ -131,13 +131,22 @@ type View struct {
 	Cursor *Cursor
 
 	// BackgroundColor when not nil, sets the terminal background color. Use
-	// nil to reset to the terminal's default background color.
+	// nil to reset to the terminal's default background color. Like the
+	// colors in Content, it's downsampled to the program's color profile.
 	BackgroundColor color.Color
 
 	// ForegroundColor when not nil, sets the terminal foreground color. Use
-	// nil to reset to the terminal's default foreground color.
+	// nil to reset to the terminal's default foreground color. Like the
+	// colors in Content, it's downsampled to the program's color profile.
 	ForegroundColor color.Color
 
+	// Palette overrides the terminal palette colors. The keys are palette
+	// indices between 0 and 255, where 0-15 are the ANSI colors. Entries
+	// that are removed are reset to the terminal's default, and so is the
+	// whole palette when the program exits. Use [RequestPaletteColor] to
+	// query the current palette.
+	Palette map[int]color.Color
+
 	// WindowTitle sets the terminal window title. Support depends on the
 	// terminal.
 	WindowTitle string
@@ -169,6 +178,16 @@ type View struct {
 	// events.
 	ReportFocus bool
 
+	// ReportColorScheme enables reporting when the terminal or operating
+	// system switches between light and dark color schemes (mode 2031). When
+	// this is enabled [ColorSchemeMsg] messages will be sent to your Update
+	// method with the current scheme, and again on every change. Bubble Tea
+	// also requests the terminal background color on every change so you'll
+	// receive a fresh [BackgroundColorMsg] as well.
+	//
+	// Note that support depends on the terminal.
+	ReportColorScheme bool
+
 	// DisableBracketedPasteMode disables bracketed paste mode for this view.
 	DisableBracketedPasteMode bool
 
@@ -176,6 +195,23 @@ type View struct {
 	// [MouseModeNone], [MouseModeCellMotion], or [MouseModeAllMotion].
 	MouseMode MouseMode
 
+	// MousePixels requests mouse coordinates with pixel precision (SGR-Pixel
+	// mode 1016) when MouseMode is enabled. On terminals that support it,
+	// [Mouse.PixelX] and [Mouse.PixelY] hold the pointer position in pixels,
+	// and the cell coordinates are computed from the cell size reported by
+	// the terminal, or from the window size in pixels if it doesn't report
+	// the cell size. If the terminal reports neither, the cell coordinates
+	// are 0. Other terminals keep reporting cell coordinates only.
+	MousePixels bool
+
+	// MousePointer sets the shape of the mouse pointer while it's over the
+	// terminal, like [MousePointerPointer] over a clickable region. Leave it
+	// empty to keep the terminal's pointer. The pointer is reset to
+	// [MousePointerDefault] when the program exits.
+	//
+	// Note that support depends on the terminal.
+	MousePointer MousePointer
+
 	// KeyboardEnhancements describes what keyboard enhancement features Bubble
 	// Tea should request from the terminal.
 	//
@@ -542,6 +578,84 @@ type Program struct {
 	// ticker is the ticker that will be used to write to the renderer.
 	ticker *time.Ticker
 
+	// caps holds the terminal capabilities reported so far. It's guarded by
+	// capsMu since it can be read from outside the event loop.
+	caps   TerminalCapabilities
+	capsMu sync.RWMutex
+
+	// probeCapabilities enables querying the terminal capabilities at
+	// startup. probing is set while waiting for the terminal to answer and
+	// is only accessed from the event loop.
+	probeCapabilities bool
+	probeTimeout      time.Duration
+	probeTimer        Timer
+	probing           bool
+
+	// startupQueries are sent to the terminal before the model is
+	// initialized. The replies, and any other messages received while
+	// waiting for them, are kept in startupMsgs and handled before the first
+	// frame is rendered.
+	startupQueries []Cmd
+	startupTimeout time.Duration
+	startupMsgs    []Msg
+
+	// keymap resolves key presses into bindings. keymapTimer fires when a
+	// pending chord times out and is only accessed from the event loop.
+	keymap      *Keymap
+	keymapTimer Timer
+
+	// gestures derives mouse gestures from mouse messages when enabled.
+	gestures *gestureTracker
+
+	// coalescing holds the input coalescing policies, if enabled.
+	coalescing *InputCoalescing
+
+	// pasteStreaming streams pastes in chunks when enabled. The paste reader
+	// queues the paste messages in pasteMsgs. When pastes can't be streamed,
+	// they're split instead, and pasteTruncated is set when the current
+	// paste was truncated.
+	pasteStreaming *PasteStreaming
+	pasteMsgs      chan Msg
+	pasteTruncated bool
+
+	// fileDrops turns pastes of dropped files into a [FileDropMsg].
+	fileDrops bool
+
+	// subs holds the subscriptions declared by the model.
+	subs subscriptions
+
+	// clock tells the time to everything that depends on it.
+	clock Clock
+
+	// debouncer holds the state of debounced and throttled commands.
+	debouncer debouncer
+
+	// commands starts commands within the concurrency limits.
+	commands cmdScheduler
+
+	// modals holds the running modal models, the innermost last, and
+	// modalSeq the id of the last one. They're only accessed from the event
+	// loop.
+	modals   []modal
+	modalSeq uint64
+
+	// mousePixels is set while the terminal reports mouse coordinates in
+	// pixels, which are turned into cells using cellSize, or using the
+	// window size in pixels and in cells if the terminal doesn't report the
+	// cell size.
+	mousePixels  bool
+	cellSize     CellSizeMsg
+	windowPixels uv.PixelSizeEvent
+	windowCells  WindowSizeMsg
+
+	// modifiedF3 holds the modifiers of the last key press if it was a
+	// modified F3, which looks like a cursor position report.
+	modifiedF3 KeyMod
+
+	// mousePixelsWait is when we started dropping mouse messages while
+	// waiting for the terminal to report a change of the pixel mouse mode.
+	mousePixelsWait time.Time
+
 	// once is used to stop the renderer.
 	once sync.Once
 
@@ -606,6 +720,7 @@ func NewProgram(model Model, opts ...ProgramOption) *Program {
 		msgs:         make(chan Msg),
 		errs:         make(chan error, 1),
 		rendererDone: make(chan struct{}),
+		clock:        systemClock{},
 	}
 
 	// Apply all options to the program.
@@ -613,6 +728,10 @@ func NewProgram(model Model, opts ...ProgramOption) *Program {
 		opt(p)
 	}
 
+	if p.gestures != nil {
+		p.gestures.clock = p.clock
+	}
+
 	// A context can be provided with a ProgramOption, but if none was provided
 	// we'll use the default background context.
 	if p.externalCtx == nil {
@@ -726,19 +845,7 @@ func (p *Program) handleCommands(cmds chan Cmd) chan struct{} {
 				// (e.g. tick commands that sleep for half a second). It's not
 				// possible to cancel them so we'll have to leak the goroutine
 				// until Cmd returns.
-				go func() {
-					// Recover from panics.
-					if !p.disableCatchPanics {
-						defer func() {
-							if r := recover(); r != nil {
-								p.recoverFromPanic(r)
-							}
-						}()
-					}
-
-					msg := cmd() // this can be long.
-					p.Send(msg)
-				}()
+				p.goCmd("", cmd, p.Send, p.recoverFromPanic)
 			}
 		}
 	}()
@@ -749,7 +856,20 @@ func (p *Program) handleCommands(cmds chan Cmd) chan struct{} {
 // eventLoop is the central message loop. It receives and handles the default
 // Bubble Tea messages, update the model and triggers redraws.
 func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
+	// Messages collected by the startup query barrier are replayed before we
+	// read any new ones. The first frame is rendered once they're handled.
+	msgs := p.msgs
+	if len(p.startupMsgs) > 0 {
+		msgs = p.replayMsgs(msgs, p.startupMsgs...)
+		p.startupMsgs = nil
+	}
+
 	for {
+		if msgs != p.msgs && len(msgs) == 0 {
+			msgs = p.msgs
+			p.render(model)
+		}
+
 		select {
 		case <-p.ctx.Done():
 			return model, nil
@@ -757,8 +877,73 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 		case err := <-p.errs:
 			return model, err
 
-		case msg := <-p.msgs:
+		case msg := <-msgs:
 			msg = p.translateInputEvent(msg)
+			p.updateCapabilities(msg)
+
+			// Drop mouse messages we can't tell the coordinates of.
+			if p.dropMouse(msg) {
+				continue
+			}
+
+			// Hand the renderer the reports it asked for to find where its
+			// frame starts.
+			if p.frameOriginReport(msg) {
+				continue
+			}
+
+			// Recognize dropped files.
+			if paste, ok := msg.(PasteMsg); ok && p.fileDrops {
+				if paths, ok := parseFileDrop(paste.Content); ok {
+					msg = FileDropMsg{Paths: paths}
+				}
+			}
+
+			// Stream pastes in chunks. Pastes that weren't streamed are
+			// split, and the chunks are handled before the end of the
+			// paste.
+			if p.pasteStreaming != nil {
+				switch paste := msg.(type) {
+				case pasteMarkerMsg:
+					select {
+					case msg = <-p.pasteMsgs:
+					default:
+						continue
+					}
+				case PasteMsg:
+					var chunks []Msg
+					chunks, p.pasteTruncated = p.pasteStreaming.split(paste.Content)
+					msgs = p.replayMsgs(msgs, chunks...)
+					continue
+				case PasteEndMsg:
+					msg = PasteEndMsg{Truncated: p.pasteTruncated}
+					p.pasteTruncated = false
+				}
+			}
+
+			// Resolve key bindings. A key that breaks a pending chord is
+			// replayed so that it's matched on its own.
+			if p.keymap != nil {
+				var replay Msg
+				msg, replay = p.resolveBinding(msg)
+				if replay != nil {
+					msgs = p.replayMsgs(msgs, replay)
+				}
+				if msg == nil {
+					continue
+				}
+			}
+
+			// Derive mouse gestures. They're handled right after the mouse
+			// message they're derived from.
+			if p.gestures != nil {
+				if gestures := p.gestures.update(msg, p.clock.Now(), p.Send); len(gestures) > 0 {
+					msgs = p.replayMsgs(msgs, gestures...)
+				}
+				if _, ok := msg.(mouseHoverMsg); ok {
+					continue
+				}
+			}
 
 			// Filter messages.
 			if p.filter != nil {
@@ -768,6 +953,14 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 				continue
 			}
 
+			// Quitting a modal closes it and hands it back to the model
+			// below.
+			if m, ok := msg.(closeModalMsg); ok {
+				if msg = p.closeModal(m.id); msg == nil {
+					continue
+				}
+			}
+
 			// Handle special internal messages.
 			switch msg := msg.(type) {
 			case QuitMsg:
@@ -781,8 +974,12 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 					p.suspend()
 				}
 
+			case capabilityProbeTimeoutMsg:
+				continue
+
 			case CapabilityMsg:
-				switch msg.Content {
+				name, _, _ := strings.Cut(msg.Content, "=")
+				switch name {
 				case "RGB", "Tc":
 					if *p.profile != colorprofile.TrueColor {
 						tc := colorprofile.TrueColor
@@ -803,8 +1000,15 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 					if msg.Value == ansi.ModeReset || msg.Value == ansi.ModeSet || msg.Value == ansi.ModePermanentlySet {
 						p.renderer.setWidthMethod(ansi.GraphemeWidth)
 					}
+				case ansi.ModeMouseExtSgrPixel:
+					// The renderer queries the mode whenever it changes it.
+					p.mousePixels = msg.Value.IsSet()
+					p.renderer.mousePixelsReported()
 				}
 
+			case CellSizeMsg:
+				p.cellSize = msg
+
 			case MouseMsg:
 				switch msg.(type) {
 				case MouseClickMsg, MouseReleaseMsg, MouseWheelMsg, MouseMotionMsg:
@@ -815,6 +1019,11 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 					}
 				}
 
+			case ColorSchemeMsg:
+				// The palette changed, so the background color most likely
+				// changed too.
+				p.execute(ansi.RequestBackgroundColor)
+
 			case readClipboardMsg:
 				p.execute(ansi.RequestSystemClipboard)
 
@@ -827,25 +1036,21 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 			case setPrimaryClipboardMsg:
 				p.execute(ansi.SetPrimaryClipboard(string(msg)))
 
-			case backgroundColorMsg:
-				p.execute(ansi.RequestBackgroundColor)
-
-			case foregroundColorMsg:
-				p.execute(ansi.RequestForegroundColor)
+			case backgroundColorMsg, foregroundColorMsg, cursorColorMsg,
+				terminalVersion, requestCapabilityMsg, paletteColorMsg:
+				p.execute(querySequence(msg))
 
-			case cursorColorMsg:
-				p.execute(ansi.RequestCursorColor)
+			case requestCursorPosMsg:
+				// The renderer asks for the cursor position too. It keeps
+				// track of the requests to tell the reports apart.
+				if !p.renderer.requestCursorPosition() {
+					p.execute(querySequence(msg))
+				}
 
 			case execMsg:
 				// NB: this blocks.
 				p.exec(msg.cmd, msg.fn)
 
-			case terminalVersion:
-				p.execute(ansi.RequestNameVersion)
-
-			case requestCapabilityMsg:
-				p.execute(ansi.RequestTermcap(string(msg)))
-
 			case BatchMsg:
 				go p.execBatchMsg(msg)
 				continue
@@ -854,15 +1059,42 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 				go p.execSequenceMsg(msg)
 				continue
 
+			case timerMsg, sequenceUntilMsg, streamMsg, timeoutMsg, retryMsg, debounceMsg, throttleMsg:
+				go p.execMsg(msg)
+				continue
+
+			case groupMsg:
+				p.handleCmdMsg(msg, nil)
+				continue
+
+			case modalMsg:
+				cmd := p.openModal(msg.model)
+				p.updateSubscriptions(msg.model)
+				select {
+				case <-p.ctx.Done():
+					return model, nil
+				case cmds <- cmd:
+				}
+				if msgs == p.msgs {
+					p.render(model)
+				}
+				continue
+
 			case WindowSizeMsg:
 				p.renderer.resize(msg.Width, msg.Height)
+				p.windowCells = msg
+				if p.mousePixels {
+					// The cell size changes along with the font size.
+					p.execute(ansi.WindowOp(ansi.RequestCellSizeWinOp) +
+						ansi.WindowOp(ansi.RequestWindowSizeWinOp))
+				}
+
+			case uv.PixelSizeEvent:
+				p.windowPixels = msg
 
 			case windowSizeMsg:
 				go p.checkResize()
 
-			case requestCursorPosMsg:
-				p.execute(ansi.RequestCursorPositionReport)
-
 			case RawMsg:
 				p.execute(fmt.Sprint(msg.Msg))
 
@@ -877,7 +1109,8 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 			}
 
 			var cmd Cmd
-			model, cmd = model.Update(msg) // run update
+			model, cmd = p.updateModel(model, msg) // run update
+			p.updateSubscriptions(p.activeModel(model))
 
 			select {
 			case <-p.ctx.Done():
@@ -885,15 +1118,35 @@ func (p *Program) eventLoop(model Model, cmds chan Cmd) (Model, error) {
 			case cmds <- cmd: // process command (if any)
 			}
 
-			p.render(model) // render view
+			if msgs == p.msgs {
+				p.render(model) // render view
+			}
 		}
 	}
 }
 
-// render renders the given view to the renderer.
+// replayMsgs returns a channel that yields the given messages before any
+// message still waiting in msgs. The event loop reads from it instead of
+// [Program.msgs] until it's drained.
+func (p *Program) replayMsgs(msgs chan Msg, replay ...Msg) chan Msg {
+	pending := 0
+	if msgs != p.msgs {
+		pending = len(msgs)
+	}
+	ch := make(chan Msg, len(replay)+pending)
+	for _, msg := range replay {
+		ch <- msg
+	}
+	for range pending {
+		ch <- <-msgs
+	}
+	return ch
+}
+
+// render renders the view of the active model to the renderer.
 func (p *Program) render(model Model) {
 	if p.renderer != nil {
-		p.renderer.render(model.View()) // send view to renderer
+		p.renderer.render(p.activeModel(model).View()) // send view to renderer
 	}
 }
 
@@ -911,19 +1164,11 @@ func (p *Program) execSequenceMsg(msg sequenceMsg) {
 		if cmd == nil {
 			continue
 		}
-		msg := cmd()
-		switch msg := msg.(type) {
-		case BatchMsg:
-			p.execBatchMsg(msg)
-		case sequenceMsg:
-			p.execSequenceMsg(msg)
-		default:
-			p.Send(msg)
-		}
+		p.execMsg(p.callCmd(cmd))
 	}
 }
 
-func (p *Program) execBatchMsg(msg BatchMsg) {
+func (p *Program) execSequenceUntilMsg(msg sequenceUntilMsg) {
 	if !p.disableCatchPanics {
 		defer func() {
 			if r := recover(); r != nil {
@@ -932,37 +1177,77 @@ func (p *Program) execBatchMsg(msg BatchMsg) {
 		}()
 	}
 
-	// Execute commands one at a time.
-	var wg sync.WaitGroup
-	for _, cmd := range msg {
+	if stopped := p.runSequenceUntil(msg); stopped != nil {
+		p.Send(stopped)
+	}
+}
+
+// runSequenceUntil executes the commands one at a time, in order, until one
+// matches, and returns the resulting [SequenceStoppedMsg], or nil. A nested
+// sequence that stops is a step that returns its SequenceStoppedMsg, so that
+// it can stop this one too.
+func (p *Program) runSequenceUntil(msg sequenceUntilMsg) Msg {
+	for i, cmd := range msg.cmds {
 		if cmd == nil {
 			continue
 		}
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-
-			if !p.disableCatchPanics {
-				defer func() {
-					if r := recover(); r != nil {
-						p.recoverFromGoPanic(r)
-					}
-				}()
-			}
+		result := p.callCmd(cmd)
+		if nested, ok := result.(sequenceUntilMsg); ok && p.ctx.Err() == nil {
+			result = p.runSequenceUntil(nested)
+		}
+		if p.ctx.Err() != nil {
+			return nil
+		}
+		if result != nil && msg.pred(result) {
+			return SequenceStoppedMsg{Step: i, Msg: result}
+		}
+		p.execMsg(result)
+	}
+	return nil
+}
 
-			msg := cmd()
-			switch msg := msg.(type) {
-			case BatchMsg:
-				p.execBatchMsg(msg)
-			case sequenceMsg:
-				p.execSequenceMsg(msg)
-			default:
-				p.Send(msg)
+func (p *Program) execBatchMsg(msg BatchMsg) {
+	if !p.disableCatchPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				p.recoverFromGoPanic(r)
 			}
 		}()
 	}
 
-	wg.Wait() // wait for all commands from batch msg to finish
+	if len(msg) == 0 {
+		return
+	}
+
+	// Execute commands concurrently, within the concurrency limits.
+	var (
+		mu       sync.Mutex
+		pending  = len(msg)
+		finished = make(chan struct{})
+	)
+	done := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if pending--; pending == 0 {
+			close(finished)
+		}
+	}
+	for _, cmd := range msg {
+		if cmd == nil {
+			done()
+			continue
+		}
+		p.goCmd("", cmd, func(msg Msg) {
+			p.handleCmdMsg(msg, done)
+		}, p.recoverFromGoPanic)
+	}
+
+	// Wait for all commands from batch msg to finish. A command that panics
+	// never finishes, but the program exits then.
+	select {
+	case <-finished:
+	case <-p.ctx.Done():
+	}
 }
 
 // shouldQuerySynchronizedOutput determines whether the terminal should be
@@ -1001,6 +1286,15 @@ func (p *Program) Run() (returnModel Model, returnErr error) {
 		return nil, errors.New("bubbletea: InitialModel cannot be nil")
 	}
 
+	// Resolve the startup queries before we touch the terminal.
+	var startupQueries string
+	if len(p.startupQueries) > 0 {
+		var err error
+		if startupQueries, err = startupQuerySequence(p.startupQueries); err != nil {
+			return p.initialModel, err
+		}
+	}
+
 	// Initialize context and teardown channel.
 	p.handlers = channelHandlers{}
 	cmds := make(chan Cmd)
@@ -1115,7 +1409,17 @@ func (p *Program) Run() (returnModel Model, returnErr error) {
 	// Start the renderer.
 	p.startRenderer()
 
-	if !p.disableRenderer && shouldQuerySynchronizedOutput(p.environ) {
+	if p.probeCapabilities {
+		if p.input != nil && !p.disableRenderer {
+			// The probe includes the synchronized output and unicode core
+			// mode queries.
+			p.startCapabilityProbe()
+		} else {
+			// Without input we can't read the replies, and they would leak
+			// into the shell after the program exits.
+			go p.Send(TerminalCapabilitiesMsg{})
+		}
+	} else if !p.disableRenderer && shouldQuerySynchronizedOutput(p.environ) {
 		// Query for synchronized updates support (mode 2026) and unicode core
 		// (mode 2027). If the terminal supports it, the renderer will enable
 		// it once we get the response.
@@ -1123,6 +1427,12 @@ func (p *Program) Run() (returnModel Model, returnErr error) {
 			ansi.RequestModeUnicodeCore)
 	}
 
+	// Wait for the terminal to answer the startup queries, if any, before
+	// initializing the model.
+	if p.startupTimeout > 0 && p.input != nil && !p.disableRenderer {
+		p.awaitStartupQueries(startupQueries)
+	}
+
 	// Initialize the program.
 	initCmd := model.Init()
 	if initCmd != nil {
@@ -1139,8 +1449,15 @@ func (p *Program) Run() (returnModel Model, returnErr error) {
 		}()
 	}
 
-	// Render the initial view.
-	p.render(model)
+	// Start the subscriptions of the initial model.
+	p.handlers.add(p.handleSubscriptions())
+	p.updateSubscriptions(model)
+
+	// Render the initial view. When there are startup messages, the event
+	// loop renders it once they're handled.
+	if len(p.startupMsgs) == 0 {
+		p.render(model)
+	}
 
 	// Handle resize events.
 	p.handlers.add(p.handleResize())
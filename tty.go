@@ -2,7 +2,9 @@ package tea
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"runtime"
 	"time"
 
 	uv "github.com/charmbracelet/ultraviolet"
@@ -71,7 +73,15 @@ func (p *Program) initInputReader(cancel bool) error {
 		return fmt.Errorf("bubbletea: could not create cancelable reader: %w", err)
 	}
 
-	drv := uv.NewTerminalReader(p.cancelReader, term)
+	var input io.Reader = p.cancelReader
+	if p.pasteStreaming != nil && runtime.GOOS != "windows" {
+		// The Windows console input must be read by the terminal reader
+		// itself.
+		p.pasteMsgs = make(chan Msg, pasteQueueSize)
+		input = newPasteReader(p.ctx, input, *p.pasteStreaming, p.pasteMsgs)
+	}
+
+	drv := uv.NewTerminalReader(input, term)
 	drv.SetLogger(p.logger)
 	p.inputScanner = drv
 	p.readLoopDone = make(chan struct{})
@@ -84,7 +94,21 @@ func (p *Program) initInputReader(cancel bool) error {
 func (p *Program) readLoop() {
 	defer close(p.readLoopDone)
 
-	if err := p.inputScanner.StreamEvents(p.ctx, p.msgs); err != nil {
+	events := p.msgs
+	if p.coalescing != nil {
+		events = make(chan Msg)
+		coalesceDone := make(chan struct{})
+		go func() {
+			defer close(coalesceDone)
+			p.coalesceInput(events)
+		}()
+		defer func() {
+			close(events)
+			<-coalesceDone
+		}()
+	}
+
+	if err := p.inputScanner.StreamEvents(p.ctx, events); err != nil {
 		select {
 		case <-p.ctx.Done():
 			return
@@ -95,9 +119,11 @@ func (p *Program) readLoop() {
 
 // waitForReadLoop waits for the cancelReader to finish its read loop.
 func (p *Program) waitForReadLoop() {
+	timeout, timer := after(p.clock, 500*time.Millisecond) //nolint:mnd
+	defer timer.Stop()
 	select {
 	case <-p.readLoopDone:
-	case <-time.After(500 * time.Millisecond): //nolint:mnd
+	case <-timeout:
 		// The read loop hangs, which means the input
 		// cancelReader's cancel function has returned true even
 		// though it was not able to cancel the read.